@@ -0,0 +1,65 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownLinkRegex = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+
+	// asciidocPlainRegex matches text that has no AsciiDoc markup in it.
+	asciidocPlainRegex = regexp.MustCompile(`^[\w .,:;/"'()=-]*$`)
+)
+
+// asciidocEscape displays s verbatim in AsciiDoc, with HTML special
+// characters still escaped in the output.
+func asciidocEscape(s string) string {
+	if asciidocPlainRegex.MatchString(s) {
+		return s
+	}
+	return "pass:c[" + strings.Replace(s, "]", `\]`, -1) + "]"
+}
+
+// asciidocCode renders s as inline monospace text.
+func asciidocCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + asciidocEscape(s) + "`"
+}
+
+// asciidocCell escapes the cell separators in s so that it can be placed in a
+// table cell.
+func asciidocCell(s string) string {
+	return escapeUnescapedPipes(s)
+}
+
+// renderAsciiDocComments is the AsciiDoc counterpart of renderComments.
+// Paragraphs, lists and indented literal blocks of godoc comments mean the
+// same in AsciiDoc, so only Markdown links are converted, and attribute
+// references are escaped so that text like "{name}" is kept as-is. If
+// markdown is false, links are left alone too.
+func renderAsciiDocComments(s []string, markdown bool) string {
+	s = filterCommentTags(s)
+	doc := strings.TrimSpace(strings.Join(s, "\n"))
+	doc = strings.Replace(doc, "{", `\{`, -1)
+	if markdown {
+		doc = markdownLinkRegex.ReplaceAllString(doc, "$2[$1]")
+	}
+	return doc
+}
+
+// asciidocLink renders a link to url, which is an xref if it is an anchor or
+// a page of the documentation, or just the text if url is empty.
+func asciidocLink(text, url string) string {
+	switch {
+	case url == "":
+		return "$$" + text + "$$"
+	case strings.HasPrefix(url, "#"):
+		return "xref:" + strings.TrimPrefix(url, "#") + "[$$" + text + "$$]"
+	case strings.Contains(url, "://"):
+		return "link:" + url + "[$$" + text + "$$]"
+	}
+	return "xref:" + url + "[$$" + text + "$$]"
+}
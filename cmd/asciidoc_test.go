@@ -0,0 +1,60 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/types"
+)
+
+func TestAsciiDocSitePages(t *testing.T) {
+	spec := testStruct(testMember("Replicas", types.Int32, `json:"replicas"`, "+kubebuilder:validation:Minimum=1"))
+	widget := testKind("Widget")
+	widget.Members = []types.Member{testMember("Spec", spec, `json:"spec"`)}
+	pkg := &apiPackage{
+		apiGroup:   "widgets.example.com",
+		apiVersion: "v1",
+		GoPackages: []*types.Package{{Path: "example.com/api/v1", Name: "v1"}},
+		Types:      []*types.Type{widget, spec},
+	}
+	setFlag(t, flFormat, formatAsciiDoc)
+	pages, err := RenderSite([]*apiPackage{pkg}, GeneratorConfig{}, true, &provenance{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make(map[string]string)
+	idRE := regexp.MustCompile(`(?m)^\[id="([^"]*)"\]$`)
+	headingRE := regexp.MustCompile(`(?m)^(=+) |^:leveloffset: ([-+]\d+)$`)
+	for _, path := range sortedKeys(pages) {
+		content := pages[path]
+		for _, m := range idRE.FindAllStringSubmatch(content, -1) {
+			if other, ok := ids[m[1]]; ok {
+				t.Errorf("%s and %s both have the id %s", other, path, m[1])
+			}
+			ids[m[1]] = path
+		}
+		// the section levels, after the level offsets, go down one at a time
+		level, offset := 1, 0
+		for _, m := range headingRE.FindAllStringSubmatch(content, -1) {
+			if m[1] == "" {
+				if m[2] == "+1" {
+					offset++
+				} else {
+					offset--
+				}
+				continue
+			}
+			if l := len(m[1]) + offset; l > level+1 {
+				t.Errorf("%s: section %q of level %d follows level %d", path, m[0], l, level)
+			} else {
+				level = l
+			}
+		}
+	}
+
+	if spec := pages["widgets.example.com/v1/index.adoc"]; !strings.Contains(spec, "Validation:\n\n* Minimum: `1`\n") {
+		t.Errorf("the validation rules are not a list:\n%s", spec)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"strings"
+
+	"k8s.io/gengo/types"
+)
+
+// renamedFromMarker on a field gives the JSON name the field had in the
+// previous version of its Kind, for the versions to be compared.
+const renamedFromMarker = "gencrdrefdocs:renamedFrom"
+
+// kindComparison aligns the fields of a Kind across the versions of its
+// group, for the comparison table.
+type kindComparison struct {
+	Kind string
+	// Versions are the versions of the group, as in groupVersions.Versions.
+	Versions []string
+	Rows     []*comparisonRow
+}
+
+// comparisonRow is a field in all the versions of a Kind.
+type comparisonRow struct {
+	// Path is the JSON path of the field in the newest version that has it,
+	// e.g. "spec.listenPort".
+	Path string
+	// Cells has one entry per version, nil for the versions that do not
+	// define the Kind, or did not have the field and did not remove it.
+	Cells []*comparisonCell
+}
+
+// comparisonCell is a field as defined in one version, or its removal.
+type comparisonCell struct {
+	// Member is the field, nil if the version removed it.
+	Member *types.Member
+	// Parent is the struct the field is declared in, whose anchor the cell
+	// links to. It is the Kind itself if the field was removed.
+	Parent *types.Type
+	Path   string
+	// Changes compare the field with the previous version defining the
+	// Kind: "added", "removed", "renamed" or "retyped".
+	Changes []string
+}
+
+// comparedField is a field of a Kind version, keyed by the JSON path it has
+// in the oldest version, which stays the same across renames.
+type comparedField struct {
+	key    string
+	path   string
+	member types.Member
+	parent *types.Type
+}
+
+// compareKind returns the comparison of the fields of k across its versions,
+// or nil if fewer than two versions define it.
+func compareKind(k *groupKind, versions []string, c GeneratorConfig, typePkgMap map[*types.Type]*apiPackage) *kindComparison {
+	defined := 0
+	for _, kv := range k.Versions {
+		if kv != nil {
+			defined++
+		}
+	}
+	if defined < 2 {
+		return nil
+	}
+
+	// fields of each version, by key
+	fields := make([]map[string]*comparedField, len(k.Versions))
+	lists := make([][]*comparedField, len(k.Versions))
+	var prev map[string]*comparedField
+	for i, kv := range k.Versions {
+		if kv == nil {
+			continue
+		}
+		lists[i] = comparedFields(kv.Type, prev, c, typePkgMap)
+		fields[i] = make(map[string]*comparedField, len(lists[i]))
+		for _, f := range lists[i] {
+			fields[i][f.key] = f
+		}
+		prev = fields[i]
+	}
+	// the fields of the newest version first, then those removed from it
+	var keys []string
+	seen := make(map[string]bool)
+	for i := len(lists) - 1; i >= 0; i-- {
+		for _, f := range lists[i] {
+			if !seen[f.key] {
+				seen[f.key] = true
+				keys = append(keys, f.key)
+			}
+		}
+	}
+
+	out := &kindComparison{Kind: k.Kind, Versions: versions}
+	for _, key := range keys {
+		row := &comparisonRow{Cells: make([]*comparisonCell, len(k.Versions))}
+		var last *comparedField
+		for i, kv := range k.Versions {
+			if kv == nil {
+				continue
+			}
+			f := fields[i][key]
+			switch {
+			case f == nil && last != nil:
+				row.Cells[i] = &comparisonCell{Parent: kv.Type, Path: last.path, Changes: []string{changeRemoved}}
+			case f != nil:
+				cell := &comparisonCell{Member: &f.member, Parent: f.parent, Path: f.path}
+				switch {
+				case last == nil && i > firstVersion(k):
+					cell.Changes = append(cell.Changes, changeAdded)
+				case last != nil:
+					if fieldName(last.member) != fieldName(f.member) {
+						cell.Changes = append(cell.Changes, "renamed")
+					}
+					if comparedTypeName(last.member.Type, typePkgMap) != comparedTypeName(f.member.Type, typePkgMap) {
+						cell.Changes = append(cell.Changes, "retyped")
+					}
+				}
+				row.Cells[i] = cell
+				row.Path = f.path
+			}
+			last = f
+		}
+		out.Rows = append(out.Rows, row)
+	}
+	return out
+}
+
+// firstVersion returns the index of the oldest version defining k.
+func firstVersion(k *groupKind) int {
+	for i, kv := range k.Versions {
+		if kv != nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// comparedFields returns the fields of the Kind t but apiVersion and kind
+// and, recursively, of the struct types of the API it references, in
+// declaration order. prev are the fields of the previous version, to key
+// the renamed fields by.
+func comparedFields(t *types.Type, prev map[string]*comparedField, c GeneratorConfig, typePkgMap map[*types.Type]*apiPackage) []*comparedField {
+	prevKeys := make(map[string]string, len(prev))
+	for _, f := range prev {
+		prevKeys[f.path] = f.key
+	}
+	// prevPath returns the path in the previous version of the field key
+	prevPath := func(key string) string {
+		if f := prev[key]; f != nil {
+			return f.path
+		}
+		return key
+	}
+
+	var out []*comparedField
+	// existing are the fields keyed after a field of the previous version
+	existing := make(map[*comparedField]bool)
+	visiting := make(map[*types.Type]bool)
+	var collect func(t *types.Type, path, key string)
+	collect = func(t *types.Type, path, key string) {
+		visiting[t] = true
+		defer delete(visiting, t)
+		for _, fm := range flattenMembers(t, c) {
+			m := fm.Member
+			if path == "" && (fieldName(m) == "apiVersion" || fieldName(m) == "kind") {
+				continue
+			}
+			f := &comparedField{
+				path:   joinFieldPath(path, fieldName(m)),
+				key:    joinFieldPath(key, fieldName(m)),
+				member: m,
+				parent: t,
+			}
+			if fm.From != nil {
+				f.parent = fm.From
+			}
+			names := []string{fieldName(m)}
+			if old, ok := lastMarkerValue(m.CommentLines, renamedFromMarker); ok {
+				names = append([]string{unquoteMarkerValue(old)}, names...)
+			}
+			for _, name := range names {
+				if k, ok := prevKeys[joinFieldPath(prevPath(key), name)]; ok {
+					f.key, existing[f] = k, true
+					break
+				}
+			}
+			out = append(out, f)
+
+			e := tryDereference(m.Type)
+			if _, local := typePkgMap[e]; local && e.Kind == types.Struct && !hideType(e, c) && !visiting[e] {
+				collect(e, f.path, f.key)
+			}
+		}
+	}
+	collect(t, "", "")
+
+	// a renamed field keeps its key, and a new field given its old name
+	// gets a key of its own
+	used := make(map[string]bool, len(out))
+	for _, claimExisting := range []bool{true, false} {
+		for _, f := range out {
+			if existing[f] != claimExisting {
+				continue
+			}
+			if used[f.key] {
+				f.key = "+" + f.path
+			}
+			used[f.key] = true
+		}
+	}
+	return out
+}
+
+// comparedTypeName names the type t of a field for comparison across the
+// versions of a group, where the types of the API only differ by package.
+func comparedTypeName(t *types.Type, typePkgMap map[*types.Type]*apiPackage) string {
+	switch t.Kind {
+	case types.Pointer:
+		return comparedTypeName(t.Elem, typePkgMap)
+	case types.Slice:
+		return "[]" + comparedTypeName(t.Elem, typePkgMap)
+	case types.Map:
+		return "map[" + comparedTypeName(t.Key, typePkgMap) + "]" + comparedTypeName(t.Elem, typePkgMap)
+	}
+	if _, ok := typePkgMap[t]; ok {
+		return t.Name.Name
+	}
+	return t.Name.String()
+}
+
+func joinFieldPath(path, name string) string {
+	return strings.TrimPrefix(path+"."+name, ".")
+}
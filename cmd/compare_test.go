@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/types"
+)
+
+// testVersionedKind returns the Kind Widget of the API version whose spec
+// has the fields ms.
+func testVersionedKind(version string, ms ...types.Member) (kind, spec *types.Type) {
+	spec = &types.Type{Name: types.Name{Package: "example.com/api/" + version, Name: "WidgetSpec"}, Kind: types.Struct, Members: ms}
+	kind = &types.Type{
+		Name:                      types.Name{Package: "example.com/api/" + version, Name: "Widget"},
+		Kind:                      types.Struct,
+		SecondClosestCommentLines: []string{"+kubebuilder:object:root=true"},
+		Members: []types.Member{
+			testMember("Kind", types.String, `json:"kind"`),
+			testMember("Spec", spec, `json:"spec"`),
+		},
+	}
+	return kind, spec
+}
+
+func TestCompareKind(t *testing.T) {
+	oldKind, oldSpec := testVersionedKind("v1beta1",
+		testMember("Port", types.Int32, `json:"port"`),
+		testMember("Size", types.Int32, `json:"size"`),
+		testMember("Color", types.String, `json:"color"`),
+	)
+	node := &types.Type{Name: types.Name{Package: "example.com/api/v1", Name: "Node"}, Kind: types.Struct}
+	node.Members = []types.Member{testMember("Next", &types.Type{Kind: types.Pointer, Elem: node}, `json:"next"`)}
+	newKind, newSpec := testVersionedKind("v1",
+		testMember("ListenPort", types.Int32, `json:"listenPort"`, "+gencrdrefdocs:renamedFrom=port"),
+		testMember("Port", types.String, `json:"port"`),
+		testMember("Size", types.String, `json:"size"`),
+		testMember("Root", node, `json:"root"`),
+	)
+	pkgs := []*apiPackage{
+		{apiGroup: "widgets.example.com", apiVersion: "v1beta1", Types: []*types.Type{oldKind, oldSpec}},
+		{apiGroup: "widgets.example.com", apiVersion: "v1", Types: []*types.Type{newKind, newSpec, node}},
+	}
+	groups, _ := buildGroupVersions(pkgs, GeneratorConfig{})
+	g := groups[0]
+	cmp := compareKind(g.Kinds[0], g.Versions, GeneratorConfig{}, extractTypeToPackageMap(pkgs))
+	if cmp == nil {
+		t.Fatal("compareKind() = nil, want a comparison")
+	}
+
+	// the changes of each row in v1, and the path of the field in v1beta1
+	// ("-" if it has none)
+	want := []string{
+		"spec: ; spec",
+		"spec.listenPort: renamed; spec.port",
+		"spec.port: added; -",
+		"spec.size: retyped; spec.size",
+		"spec.root: added; -",
+		"spec.root.next: added; -",
+		"spec.color: removed; spec.color",
+	}
+	var got []string
+	for _, row := range cmp.Rows {
+		old, cur := row.Cells[0], row.Cells[1]
+		oldPath := "-"
+		if old != nil {
+			oldPath = old.Path
+		}
+		got = append(got, row.Path+": "+strings.Join(cur.Changes, ",")+"; "+oldPath)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rows =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCompareKindOfSingleVersion(t *testing.T) {
+	kind, spec := testVersionedKind("v1", testMember("Size", types.Int32, `json:"size"`))
+	pkgs := []*apiPackage{{apiGroup: "widgets.example.com", apiVersion: "v1", Types: []*types.Type{kind, spec}}}
+	groups, _ := buildGroupVersions(pkgs, GeneratorConfig{})
+	if cmp := compareKind(groups[0].Kinds[0], groups[0].Versions, GeneratorConfig{}, extractTypeToPackageMap(pkgs)); cmp != nil {
+		t.Errorf("compareKind() = %+v, want nil", cmp)
+	}
+}
+
+func TestComparedFieldTypes(t *testing.T) {
+	labels := &types.Type{Name: types.Name{Name: "map[string]string"}, Kind: types.Map, Key: types.String, Elem: types.String}
+	oldKind, oldSpec := testVersionedKind("v1beta1", testMember("Labels", types.String, `json:"labels"`))
+	newKind, newSpec := testVersionedKind("v1", testMember("Labels", labels, `json:"labels"`))
+	pkgs := []*apiPackage{
+		{apiGroup: "widgets.example.com", apiVersion: "v1beta1", Types: []*types.Type{oldKind, oldSpec}},
+		{apiGroup: "widgets.example.com", apiVersion: "v1", Types: []*types.Type{newKind, newSpec}},
+	}
+	for _, pkg := range pkgs {
+		pkg.GoPackages = []*types.Package{{Path: "example.com/api/" + pkg.apiVersion, Name: pkg.apiVersion}}
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{formatMarkdown, " _map\\[string\\]string_ **retyped** |"},
+		{formatAsciiDoc, " _$$map[string]string$$_ *retyped*\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			r := newRenderer(pkgs, GeneratorConfig{}, tt.format)
+			var b bytes.Buffer
+			if err := r.render(&b, &page{Kind: pageSingle, Packages: pkgs}, &provenance{}); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(b.String(), tt.want) {
+				t.Errorf("output does not contain %q:\n%s", tt.want, b.String())
+			}
+		})
+	}
+}
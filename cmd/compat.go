@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+)
+
+// compatPolicy is the policy file of the check-compat command.
+type compatPolicy struct {
+	// CheckAlphaVersions also checks the changes of the alpha versions, which
+	// are exempt by default.
+	CheckAlphaVersions bool `json:"checkAlphaVersions"`
+
+	// Exceptions are the incompatible changes that are allowed.
+	Exceptions []compatException `json:"exceptions"`
+}
+
+// compatException allows the incompatible changes matching all its
+// non-empty attributes.
+type compatException struct {
+	Rule       string `json:"rule"`
+	APIVersion string `json:"apiVersion"`
+	Type       string `json:"type"`
+	Field      string `json:"field"`
+	// Reason documents why the change is allowed.
+	Reason string `json:"reason"`
+}
+
+func (e *compatException) matches(i *compatIssue) bool {
+	for _, v := range [][2]string{
+		{e.Rule, i.Rule},
+		{e.APIVersion, i.APIVersion},
+		{e.Type, i.Type},
+		{e.Field, i.Field},
+	} {
+		if v[0] != "" && v[0] != v[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// compatIssue is a change that breaks the compatibility of the API.
+type compatIssue struct {
+	*apiChange
+	// Rule is the compatibility rule the change breaks.
+	Rule string `json:"rule"`
+	// Exemption is why the change is allowed, if it is.
+	Exemption string `json:"exemption,omitempty"`
+}
+
+// compatReport is the result of the check-compat command.
+type compatReport struct {
+	Base       string         `json:"base"`
+	Head       string         `json:"head"`
+	Violations []*compatIssue `json:"violations"`
+	Exempted   []*compatIssue `json:"exempted"`
+}
+
+// compatRules are the rules compatRule returns.
+var compatRules = []string{
+	"version-removed",
+	"kind-removed",
+	"kind-unserved",
+	"field-removed",
+	"required-field-added",
+	"field-renamed",
+	"field-retyped",
+	"field-made-required",
+	"enum-value-removed",
+	"enum-added",
+}
+
+// compatRule returns the Kubernetes API compatibility rule the change c
+// breaks, or "" if it is compatible. base is the tree before the change.
+func compatRule(c *apiChange, base *renderer) string {
+	switch {
+	case c.Subject == "version" && c.Change == changeRemoved:
+		return "version-removed"
+	case c.Subject == "kind" && c.Change == changeRemoved:
+		if servedKind(base, c.APIVersion, c.Type) {
+			return "kind-removed"
+		}
+	case c.Subject == "kind" && c.Aspect == "served" && c.New == "false":
+		return "kind-unserved"
+	case c.Subject == "field" && c.Change == changeRemoved:
+		return "field-removed"
+	case c.Subject == "field" && c.Change == changeAdded && c.New == "required":
+		return "required-field-added"
+	case c.Subject == "field" && c.Aspect == "jsonName":
+		return "field-renamed"
+	case c.Subject == "field" && c.Aspect == "type":
+		return "field-retyped"
+	case c.Subject == "field" && c.Aspect == "optional" && c.New == "required":
+		return "field-made-required"
+	case c.Subject == "enumValue" && c.Change == changeRemoved:
+		return "enum-value-removed"
+	case c.Subject == "enum" && c.Change == changeAdded:
+		return "enum-added"
+	}
+	return ""
+}
+
+// servedKind reports whether the Kind named kind of the API version id of
+// the tree r is served.
+func servedKind(r *renderer, id, kind string) bool {
+	for t, v := range r.kindVersions {
+		if pkg := r.typePkgMap[t]; pkg != nil && pkg.groupVersion() == id && t.Name.Name == kind {
+			return v.Served
+		}
+	}
+	return true
+}
+
+// isAlphaVersion reports whether the API version id, e.g.
+// "widgets.example.com/v1alpha1", is an alpha version.
+func isAlphaVersion(id string) bool {
+	ok, _ := path.Match("v*alpha*", id[strings.LastIndex(id, "/")+1:])
+	return ok
+}
+
+// checkCompat classifies the changes from base to head.
+func checkCompat(d *apiDiff, base *renderer, policy compatPolicy) *compatReport {
+	report := &compatReport{Base: d.Base, Head: d.Head, Violations: []*compatIssue{}, Exempted: []*compatIssue{}}
+	for _, c := range d.Changes {
+		rule := compatRule(c, base)
+		if rule == "" {
+			continue
+		}
+		issue := &compatIssue{apiChange: c, Rule: rule}
+		if isAlphaVersion(c.APIVersion) && !policy.CheckAlphaVersions {
+			issue.Exemption = "alpha version"
+		}
+		for _, e := range policy.Exceptions {
+			if issue.Exemption == "" && e.matches(issue) {
+				issue.Exemption = e.Reason
+				if issue.Exemption == "" {
+					issue.Exemption = "policy exception"
+				}
+			}
+		}
+		if issue.Exemption != "" {
+			report.Exempted = append(report.Exempted, issue)
+		} else {
+			report.Violations = append(report.Violations, issue)
+		}
+	}
+	return report
+}
+
+func loadCompatPolicy(path string) (compatPolicy, error) {
+	var policy compatPolicy
+	if path == "" {
+		return policy, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return policy, errors.Wrap(err, "failed to open policy file")
+	}
+	defer f.Close()
+	d := json.NewDecoder(f)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&policy); err != nil {
+		return policy, errors.Wrap(err, "failed to parse policy file")
+	}
+	for i, e := range policy.Exceptions {
+		if e.Rule != "" && !containsString(compatRules, e.Rule) {
+			return policy, errors.Errorf("exception %d of the policy file: unknown rule %q, must be one of %s", i, e.Rule, strings.Join(compatRules, ", "))
+		}
+	}
+	return policy, nil
+}
+
+// checkCompatCommand reports the incompatible changes of the API between
+// two trees, and exits with a non-zero status if any is not exempted.
+func checkCompatCommand() {
+	config := readConfigFromFile()
+	policy, err := loadCompatPolicy(*flPolicy)
+	if err != nil {
+		klog.Fatalf("%+v", err)
+	}
+	base, head, d, err := loadAPITrees(config)
+	if err != nil {
+		klog.Fatalf("%+v", err)
+	}
+	d.Changes = append(d.Changes, diffAPI(base, head)...)
+	report := checkCompat(d, base, policy)
+
+	s, err := renderCompatReport(report, config)
+	if err != nil {
+		klog.Fatalf("%+v", err)
+	}
+	writeCommandOutput(s)
+	if n := len(report.Violations); n > 0 {
+		klog.Errorf("found %d incompatible API changes", n)
+		klog.Flush()
+		os.Exit(1)
+	}
+}
+
+// renderCompatReport renders report with the "compat" template of the
+// -format, or as JSON.
+func renderCompatReport(report *compatReport, config GeneratorConfig) (string, error) {
+	var b bytes.Buffer
+	switch format := outputFormat(); format {
+	case formatJSON:
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(report); err != nil {
+			return "", errors.Wrap(err, "failed to encode the report")
+		}
+		return b.String(), nil
+	case formatMarkdown, formatHTML:
+		tpl, err := parseTemplates(format, newRenderer(nil, config, format).templateFuncs(nil))
+		if err != nil {
+			return "", err
+		}
+		if err := tpl.ExecuteTemplate(&b, "compat", map[string]interface{}{"report": report}); err != nil {
+			return "", errors.Wrap(err, "compat template execution error")
+		}
+		return postProcess(b.String(), config), nil
+	default:
+		return "", errors.Errorf("the report can be rendered as %s, %s or %s, not %s", formatMarkdown, formatHTML, formatJSON, format)
+	}
+}
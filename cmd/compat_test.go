@@ -0,0 +1,139 @@
+package main
+
+import "testing"
+
+func TestCompatRule(t *testing.T) {
+	base := testTree("v1", testKind("Widget"), testKind("Gadget", "+kubebuilder:unservedversion"))
+	const v1 = "widgets.example.com/v1"
+
+	tests := []struct {
+		name   string
+		change apiChange
+		want   string
+	}{
+		{"version removed", apiChange{Change: changeRemoved, Subject: "version", APIVersion: v1}, "version-removed"},
+		{"version added", apiChange{Change: changeAdded, Subject: "version", APIVersion: v1}, ""},
+		{"served kind removed", apiChange{Change: changeRemoved, Subject: "kind", APIVersion: v1, Type: "Widget"}, "kind-removed"},
+		{"unserved kind removed", apiChange{Change: changeRemoved, Subject: "kind", APIVersion: v1, Type: "Gadget"}, ""},
+		{"kind added", apiChange{Change: changeAdded, Subject: "kind", APIVersion: v1, Type: "Gizmo"}, ""},
+		{"kind unserved", apiChange{Change: changeChanged, Subject: "kind", APIVersion: v1, Type: "Widget", Aspect: "served", Old: "true", New: "false"}, "kind-unserved"},
+		{"kind served", apiChange{Change: changeChanged, Subject: "kind", APIVersion: v1, Type: "Gadget", Aspect: "served", Old: "false", New: "true"}, ""},
+		{"type removed", apiChange{Change: changeRemoved, Subject: "type", APIVersion: v1, Type: "WidgetSpec"}, ""},
+		{"field removed", apiChange{Change: changeRemoved, Subject: "field", APIVersion: v1, Type: "WidgetSpec", Field: "size"}, "field-removed"},
+		{"required field added", apiChange{Change: changeAdded, Subject: "field", APIVersion: v1, Type: "WidgetSpec", Field: "size", New: "required"}, "required-field-added"},
+		{"optional field added", apiChange{Change: changeAdded, Subject: "field", APIVersion: v1, Type: "WidgetSpec", Field: "size", New: "optional"}, ""},
+		{"field renamed", apiChange{Change: changeChanged, Subject: "field", APIVersion: v1, Type: "WidgetSpec", Field: "size", Aspect: "jsonName", Old: "sz", New: "size"}, "field-renamed"},
+		{"field retyped", apiChange{Change: changeChanged, Subject: "field", APIVersion: v1, Type: "WidgetSpec", Field: "size", Aspect: "type", Old: "int32", New: "string"}, "field-retyped"},
+		{"field made required", apiChange{Change: changeChanged, Subject: "field", APIVersion: v1, Type: "WidgetSpec", Field: "size", Aspect: "optional", Old: "optional", New: "required"}, "field-made-required"},
+		{"field made optional", apiChange{Change: changeChanged, Subject: "field", APIVersion: v1, Type: "WidgetSpec", Field: "size", Aspect: "optional", Old: "required", New: "optional"}, ""},
+		{"enum value removed", apiChange{Change: changeRemoved, Subject: "enumValue", APIVersion: v1, Type: "Mode", Old: "Slow"}, "enum-value-removed"},
+		{"enum value added", apiChange{Change: changeAdded, Subject: "enumValue", APIVersion: v1, Type: "Mode", New: "Fast"}, ""},
+		{"enum added", apiChange{Change: changeAdded, Subject: "enum", APIVersion: v1, Type: "WidgetSpec", Field: "mode", New: "Fast, Slow"}, "enum-added"},
+		{"enum removed", apiChange{Change: changeRemoved, Subject: "enum", APIVersion: v1, Type: "WidgetSpec", Field: "mode", Old: "Fast, Slow"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.change
+			if got := compatRule(&c, base); got != tt.want {
+				t.Errorf("compatRule() = %q, want %q", got, tt.want)
+			}
+			if tt.want != "" && !containsString(compatRules, tt.want) {
+				t.Errorf("%q is not in compatRules", tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckCompat(t *testing.T) {
+	base := testTree("v1", testKind("Widget"))
+	fieldRemoved := func(version string) *apiChange {
+		return &apiChange{Change: changeRemoved, Subject: "field", APIVersion: "widgets.example.com/" + version, Type: "WidgetSpec", Field: "size"}
+	}
+
+	tests := []struct {
+		name   string
+		change *apiChange
+		policy compatPolicy
+		// exemption is the expected exemption, "" for a violation
+		exemption string
+	}{
+		{"violation", fieldRemoved("v1"), compatPolicy{}, ""},
+		{"alpha version", fieldRemoved("v1alpha1"), compatPolicy{}, "alpha version"},
+		{"checked alpha version", fieldRemoved("v2alpha1"), compatPolicy{CheckAlphaVersions: true}, ""},
+		{"beta version", fieldRemoved("v1beta1"), compatPolicy{}, ""},
+		{
+			"exception",
+			fieldRemoved("v1"),
+			compatPolicy{Exceptions: []compatException{{Rule: "field-removed", Type: "WidgetSpec", Field: "size", Reason: "never set"}}},
+			"never set",
+		},
+		{
+			"exception without reason",
+			fieldRemoved("v1"),
+			compatPolicy{Exceptions: []compatException{{APIVersion: "widgets.example.com/v1"}}},
+			"policy exception",
+		},
+		{
+			"exception of another field",
+			fieldRemoved("v1"),
+			compatPolicy{Exceptions: []compatException{{Rule: "field-removed", Field: "color"}}},
+			"",
+		},
+		{
+			"exception of another rule",
+			fieldRemoved("v1"),
+			compatPolicy{Exceptions: []compatException{{Rule: "field-renamed", Field: "size"}}},
+			"",
+		},
+		{
+			"first matching exception",
+			fieldRemoved("v1"),
+			compatPolicy{Exceptions: []compatException{{Field: "color", Reason: "a"}, {Field: "size", Reason: "b"}, {Reason: "c"}}},
+			"b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &apiDiff{Base: "v1.0.0", Head: "v1.1.0", Changes: []*apiChange{
+				tt.change,
+				{Change: changeAdded, Subject: "field", APIVersion: tt.change.APIVersion, Type: "WidgetSpec", Field: "color", New: "optional"},
+			}}
+			report := checkCompat(d, base, tt.policy)
+			if report.Base != d.Base || report.Head != d.Head {
+				t.Errorf("report of %s..%s, want %s..%s", report.Base, report.Head, d.Base, d.Head)
+			}
+			if n := len(report.Violations) + len(report.Exempted); n != 1 {
+				t.Fatalf("got %d issues, want 1", n)
+			}
+			var issue *compatIssue
+			if tt.exemption == "" {
+				if len(report.Violations) != 1 {
+					t.Fatalf("got no violation, want one")
+				}
+				issue = report.Violations[0]
+			} else {
+				if len(report.Exempted) != 1 {
+					t.Fatalf("got no exempted issue, want one")
+				}
+				issue = report.Exempted[0]
+			}
+			if issue.Rule != "field-removed" || issue.Exemption != tt.exemption {
+				t.Errorf("got rule %q exempted by %q, want field-removed exempted by %q", issue.Rule, issue.Exemption, tt.exemption)
+			}
+		})
+	}
+}
+
+func TestIsAlphaVersion(t *testing.T) {
+	for id, want := range map[string]bool{
+		"widgets.example.com/v1alpha1": true,
+		"widgets.example.com/v2alpha3": true,
+		"widgets.example.com/v1beta1":  false,
+		"widgets.example.com/v1":       false,
+		"v1alpha1":                     true,
+	} {
+		if got := isAlphaVersion(id); got != want {
+			t.Errorf("isAlphaVersion(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
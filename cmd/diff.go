@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/gengo/types"
+	"k8s.io/klog"
+)
+
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeChanged = "changed"
+)
+
+// apiDiff lists the changes of the API from a base tree to a head tree.
+type apiDiff struct {
+	Base    string       `json:"base"`
+	Head    string       `json:"head"`
+	Changes []*apiChange `json:"changes"`
+}
+
+// apiChange is a change of the API, as rendered by the diff command.
+type apiChange struct {
+	// Change is "added", "removed" or "changed".
+	Change string `json:"change"`
+	// Subject is what the change is about: "version", "kind", "type",
+	// "field", "enum" or "enumValue".
+	Subject string `json:"subject"`
+	// APIVersion is the API group version, e.g. "widgets.example.com/v1".
+	APIVersion string `json:"apiVersion"`
+	Type       string `json:"type,omitempty"`
+	// Field is the JSON name of the field, in the head tree unless the
+	// field was removed.
+	Field string `json:"field,omitempty"`
+	// Aspect is what changed about a field, "jsonName", "type" or
+	// "optional", or about a Kind, "served".
+	Aspect string `json:"aspect,omitempty"`
+	// Old and New are the values before and after the change of an Aspect,
+	// the enum value removed or added, or the allowed values of the enum
+	// removed or added. New is also the optionality of an added field.
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// diffAPI compares the API packages of two trees.
+func diffAPI(base, head *renderer) []*apiChange {
+	basePkgs, headPkgs := packagesByIdentifier(base.pkgs), packagesByIdentifier(head.pkgs)
+	var out []*apiChange
+	for _, id := range unionKeys(basePkgs, headPkgs) {
+		b, h := basePkgs[id], headPkgs[id]
+		switch {
+		case b == nil:
+			out = append(out, &apiChange{Change: changeAdded, Subject: "version", APIVersion: id})
+		case h == nil:
+			out = append(out, &apiChange{Change: changeRemoved, Subject: "version", APIVersion: id})
+		default:
+			out = append(out, diffPackage(base, head, b, h)...)
+		}
+	}
+	return out
+}
+
+func diffPackage(base, head *renderer, b, h *apiPackage) []*apiChange {
+	id := h.groupVersion()
+	baseTypes, headTypes := typesByName(b, base.config), typesByName(h, head.config)
+	subject := func(t *types.Type) string {
+		if isExportedType(t) {
+			return "kind"
+		}
+		return "type"
+	}
+
+	var out []*apiChange
+	for _, name := range unionKeys(baseTypes, headTypes) {
+		bt, ht := baseTypes[name], headTypes[name]
+		switch {
+		case bt == nil:
+			out = append(out, &apiChange{Change: changeAdded, Subject: subject(ht), APIVersion: id, Type: name})
+			continue
+		case ht == nil:
+			out = append(out, &apiChange{Change: changeRemoved, Subject: subject(bt), APIVersion: id, Type: name})
+			continue
+		}
+		if isExportedType(bt) != isExportedType(ht) {
+			change := changeAdded
+			if isExportedType(bt) {
+				change = changeRemoved
+			}
+			out = append(out, &apiChange{Change: change, Subject: "kind", APIVersion: id, Type: name})
+		} else if bv, hv := base.kindVersions[bt], head.kindVersions[ht]; bv != nil && hv != nil && bv.Served != hv.Served {
+			out = append(out, &apiChange{Change: changeChanged, Subject: "kind", APIVersion: id, Type: name,
+				Aspect: "served", Old: strconv.FormatBool(bv.Served), New: strconv.FormatBool(hv.Served)})
+		}
+		for _, c := range diffEnum(typeEnum(bt, b), typeEnum(ht, h)) {
+			c.APIVersion, c.Type = id, name
+			out = append(out, c)
+		}
+		for _, c := range diffFields(base, head, bt, ht) {
+			c.APIVersion, c.Type = id, name
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// diffFields compares the fields of the struct types bt and ht, matching
+// them by Go name, or by JSON name if the Go name changed.
+func diffFields(base, head *renderer, bt, ht *types.Type) []*apiChange {
+	baseFields := flattenMembers(bt, base.config)
+	matched := make([]bool, len(baseFields))
+	find := func(match func(m types.Member) bool) *flatMember {
+		for i, f := range baseFields {
+			if !matched[i] && match(f.Member) {
+				matched[i] = true
+				return &baseFields[i]
+			}
+		}
+		return nil
+	}
+
+	var out []*apiChange
+	for _, hf := range flattenMembers(ht, head.config) {
+		hm := hf.Member
+		bf := find(func(m types.Member) bool { return m.Name == hm.Name })
+		if bf == nil {
+			bf = find(func(m types.Member) bool { return fieldName(m) == fieldName(hm) })
+		}
+		if bf == nil {
+			out = append(out, &apiChange{Change: changeAdded, Subject: "field", Field: fieldName(hm), New: optionality(hm)})
+			continue
+		}
+		bm := bf.Member
+		changed := func(aspect, old, new string) {
+			if old != new {
+				out = append(out, &apiChange{Change: changeChanged, Subject: "field", Field: fieldName(hm), Aspect: aspect, Old: old, New: new})
+			}
+		}
+		changed("jsonName", fieldName(bm), fieldName(hm))
+		changed("type", diffTypeName(bm.Type, base.typePkgMap), diffTypeName(hm.Type, head.typePkgMap))
+		changed("optional", optionality(bm), optionality(hm))
+		// the enum of the type of the field is compared with the type
+		if !hasMarker(bm.CommentLines, enumMarker) && !hasMarker(hm.CommentLines, enumMarker) {
+			continue
+		}
+		for _, c := range diffEnum(memberEnum(bm, base.typePkgMap), memberEnum(hm, head.typePkgMap)) {
+			c.Field = fieldName(hm)
+			out = append(out, c)
+		}
+	}
+	for i, f := range baseFields {
+		if !matched[i] {
+			out = append(out, &apiChange{Change: changeRemoved, Subject: "field", Field: fieldName(f.Member)})
+		}
+	}
+	return out
+}
+
+func optionality(m types.Member) string {
+	if isRequiredMember(m) {
+		return "required"
+	}
+	return "optional"
+}
+
+// diffEnum compares the allowed values of two enums, either of which may be
+// nil.
+func diffEnum(b, h *enumModel) []*apiChange {
+	values := func(e *enumModel) (map[string]bool, string) {
+		out := make(map[string]bool)
+		var list []string
+		if e != nil {
+			for _, v := range e.Allowed() {
+				out[v.Value] = true
+				list = append(list, v.Value)
+			}
+		}
+		return out, strings.Join(list, ", ")
+	}
+	baseValues, baseList := values(b)
+	headValues, headList := values(h)
+	// not an enum on one side, any value is allowed there
+	switch {
+	case len(baseValues) == 0 && len(headValues) == 0:
+		return nil
+	case len(baseValues) == 0:
+		return []*apiChange{{Change: changeAdded, Subject: "enum", New: headList}}
+	case len(headValues) == 0:
+		return []*apiChange{{Change: changeRemoved, Subject: "enum", Old: baseList}}
+	}
+	var out []*apiChange
+	for _, v := range unionKeys(baseValues, headValues) {
+		switch {
+		case !baseValues[v]:
+			out = append(out, &apiChange{Change: changeAdded, Subject: "enumValue", New: v})
+		case !headValues[v]:
+			out = append(out, &apiChange{Change: changeRemoved, Subject: "enumValue", Old: v})
+		}
+	}
+	return out
+}
+
+// diffTypeName names the type t of a field for comparison across trees,
+// where the Go packages may not have the same import path. Pointers are
+// ignored, since they do not change the serialized type.
+func diffTypeName(t *types.Type, typePkgMap map[*types.Type]*apiPackage) string {
+	switch t.Kind {
+	case types.Pointer:
+		return diffTypeName(t.Elem, typePkgMap)
+	case types.Slice:
+		return "[]" + diffTypeName(t.Elem, typePkgMap)
+	case types.Map:
+		return "map[" + diffTypeName(t.Key, typePkgMap) + "]" + diffTypeName(t.Elem, typePkgMap)
+	}
+	if pkg, ok := typePkgMap[t]; ok {
+		return pkg.groupVersion() + "." + t.Name.Name
+	}
+	return t.Name.String()
+}
+
+func packagesByIdentifier(pkgs []*apiPackage) map[string]*apiPackage {
+	out := make(map[string]*apiPackage, len(pkgs))
+	for _, p := range pkgs {
+		out[p.groupVersion()] = p
+	}
+	return out
+}
+
+func typesByName(pkg *apiPackage, c GeneratorConfig) map[string]*types.Type {
+	out := make(map[string]*types.Type)
+	for _, t := range visibleTypes(pkg.Types, c) {
+		out[t.Name.Name] = t
+	}
+	return out
+}
+
+// unionKeys returns the keys of maps with string keys, sorted.
+func unionKeys(maps ...interface{}) []string {
+	seen := make(map[string]bool)
+	for _, m := range maps {
+		for _, k := range reflect.ValueOf(m).MapKeys() {
+			seen[k.String()] = true
+		}
+	}
+	out := make([]string, 0, len(seen))
+	for k := range seen {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// loadAPITrees parses the base and head trees to compare, given by
+// -base-api-dir and -api-dir, or by git revisions of the repository of
+// -api-dir.
+func loadAPITrees(config GeneratorConfig) (base, head *renderer, d *apiDiff, err error) {
+	if *flAPIDir == "" {
+		return nil, nil, nil, errors.New("-api-dir not specified")
+	}
+	parse := func(dir, rev string) (*renderer, error) {
+		var pkgs []*apiPackage
+		var err error
+		if rev == "" {
+			pkgs, err = parseAPIDir(dir)
+		} else {
+			var c *apiCheckout
+			if c, err = checkoutAPIDir(dir, rev); err != nil {
+				return nil, err
+			}
+			defer c.remove()
+			pkgs, err = c.parse()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return newRenderer(pkgs, config, outputFormat()), nil
+	}
+
+	d = &apiDiff{Changes: []*apiChange{}}
+	switch {
+	case *flBaseRev != "" && *flBaseAPIDir != "":
+		return nil, nil, nil, errors.New("only one of -base-rev or -base-api-dir can be specified")
+	case *flBaseRev != "":
+		d.Base = *flBaseRev
+		base, err = parse(*flAPIDir, *flBaseRev)
+	case *flBaseAPIDir != "":
+		if *flHeadRev != "" {
+			return nil, nil, nil, errors.New("-head-rev requires -base-rev")
+		}
+		d.Base = *flBaseAPIDir
+		base, err = parse(*flBaseAPIDir, "")
+	default:
+		return nil, nil, nil, errors.New("-base-rev or -base-api-dir must be specified")
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	d.Head = *flAPIDir
+	if *flHeadRev != "" {
+		d.Head = *flHeadRev
+	}
+	if head, err = parse(*flAPIDir, *flHeadRev); err != nil {
+		return nil, nil, nil, err
+	}
+	return base, head, d, nil
+}
+
+// diffCommand reports the changes of the API between two trees.
+func diffCommand() {
+	config := readConfigFromFile()
+	base, head, d, err := loadAPITrees(config)
+	if err != nil {
+		klog.Fatalf("%+v", err)
+	}
+	d.Changes = append(d.Changes, diffAPI(base, head)...)
+	s, err := renderDiff(d, config)
+	if err != nil {
+		klog.Fatalf("%+v", err)
+	}
+	writeCommandOutput(s)
+}
+
+// renderDiff renders d with the "diff" template of the -format, or as JSON.
+func renderDiff(d *apiDiff, config GeneratorConfig) (string, error) {
+	var b bytes.Buffer
+	switch format := outputFormat(); format {
+	case formatJSON:
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(d); err != nil {
+			return "", errors.Wrap(err, "failed to encode the diff")
+		}
+		return b.String(), nil
+	case formatMarkdown, formatHTML:
+		tpl, err := parseTemplates(format, newRenderer(nil, config, format).templateFuncs(nil))
+		if err != nil {
+			return "", err
+		}
+		if err := tpl.ExecuteTemplate(&b, "diff", map[string]interface{}{"diff": d}); err != nil {
+			return "", errors.Wrap(err, "diff template execution error")
+		}
+		return postProcess(b.String(), config), nil
+	default:
+		return "", errors.Errorf("the diff can be rendered as %s, %s or %s, not %s", formatMarkdown, formatHTML, formatJSON, format)
+	}
+}
+
+// writeCommandOutput writes the output of a command to -out-file, or to
+// the standard output.
+func writeCommandOutput(s string) {
+	if *flOutFile != "" {
+		outputToFile(s)
+		return
+	}
+	fmt.Fprint(os.Stdout, s)
+}
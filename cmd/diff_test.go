@@ -0,0 +1,143 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/types"
+)
+
+// testTree returns the renderer of an API version made of ts.
+func testTree(version string, ts ...*types.Type) *renderer {
+	pkg := &apiPackage{apiGroup: "widgets.example.com", apiVersion: version, Types: ts}
+	return newRenderer([]*apiPackage{pkg}, GeneratorConfig{}, formatMarkdown)
+}
+
+func TestDiffFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		base, head []types.Member
+		want       []*apiChange
+	}{
+		{
+			name: "unchanged",
+			base: []types.Member{testMember("Size", types.Int32, `json:"size"`)},
+			head: []types.Member{testMember("Size", types.Int32, `json:"size"`)},
+		},
+		{
+			name: "renamed by JSON name",
+			base: []types.Member{testMember("Size", types.Int32, `json:"size"`)},
+			head: []types.Member{testMember("Size", types.Int32, `json:"sizeInBytes"`)},
+			want: []*apiChange{{Change: changeChanged, Subject: "field", Field: "sizeInBytes", Aspect: "jsonName", Old: "size", New: "sizeInBytes"}},
+		},
+		{
+			name: "renamed by Go name",
+			base: []types.Member{testMember("Size", types.Int32, `json:"size"`)},
+			head: []types.Member{testMember("SizeInBytes", types.Int32, `json:"size"`)},
+		},
+		{
+			name: "retyped",
+			base: []types.Member{testMember("Size", types.Int32, `json:"size"`)},
+			head: []types.Member{testMember("Size", types.String, `json:"size"`)},
+			want: []*apiChange{{Change: changeChanged, Subject: "field", Field: "size", Aspect: "type", Old: "int32", New: "string"}},
+		},
+		{
+			name: "made a pointer",
+			base: []types.Member{testMember("Size", types.Int32, `json:"size"`)},
+			head: []types.Member{testMember("Size", &types.Type{Kind: types.Pointer, Elem: types.Int32}, `json:"size"`)},
+		},
+		{
+			name: "made required",
+			base: []types.Member{testMember("Size", types.Int32, `json:"size,omitempty"`)},
+			head: []types.Member{testMember("Size", types.Int32, `json:"size"`)},
+			want: []*apiChange{{Change: changeChanged, Subject: "field", Field: "size", Aspect: "optional", Old: "optional", New: "required"}},
+		},
+		{
+			name: "made required by marker",
+			base: []types.Member{testMember("Size", types.Int32, `json:"size,omitempty"`)},
+			head: []types.Member{testMember("Size", types.Int32, `json:"size,omitempty"`, "+kubebuilder:validation:Required")},
+			want: []*apiChange{{Change: changeChanged, Subject: "field", Field: "size", Aspect: "optional", Old: "optional", New: "required"}},
+		},
+		{
+			name: "made optional",
+			base: []types.Member{testMember("Size", types.Int32, `json:"size"`)},
+			head: []types.Member{testMember("Size", types.Int32, `json:"size"`, "+optional")},
+			want: []*apiChange{{Change: changeChanged, Subject: "field", Field: "size", Aspect: "optional", Old: "required", New: "optional"}},
+		},
+		{
+			name: "added and removed",
+			base: []types.Member{testMember("Size", types.Int32, `json:"size"`)},
+			head: []types.Member{
+				testMember("Color", types.String, `json:"color"`),
+				testMember("Shape", types.String, `json:"shape,omitempty"`),
+			},
+			want: []*apiChange{
+				{Change: changeAdded, Subject: "field", Field: "color", New: "required"},
+				{Change: changeAdded, Subject: "field", Field: "shape", New: "optional"},
+				{Change: changeRemoved, Subject: "field", Field: "size"},
+			},
+		},
+		{
+			name: "enum value removed",
+			base: []types.Member{testMember("Mode", types.String, `json:"mode"`, "+kubebuilder:validation:Enum=Fast;Slow")},
+			head: []types.Member{testMember("Mode", types.String, `json:"mode"`, "+kubebuilder:validation:Enum=Fast")},
+			want: []*apiChange{{Change: changeRemoved, Subject: "enumValue", Field: "mode", Old: "Slow"}},
+		},
+		{
+			name: "enum added",
+			base: []types.Member{testMember("Mode", types.String, `json:"mode"`)},
+			head: []types.Member{testMember("Mode", types.String, `json:"mode"`, "+kubebuilder:validation:Enum=Fast;Slow")},
+			want: []*apiChange{{Change: changeAdded, Subject: "enum", Field: "mode", New: "Fast, Slow"}},
+		},
+		{
+			name: "enum removed",
+			base: []types.Member{testMember("Mode", types.String, `json:"mode"`, "+kubebuilder:validation:Enum=Fast;Slow")},
+			head: []types.Member{testMember("Mode", types.String, `json:"mode"`)},
+			want: []*apiChange{{Change: changeRemoved, Subject: "enum", Field: "mode", Old: "Fast, Slow"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bt, ht := testStruct(tt.base...), testStruct(tt.head...)
+			base, head := testTree("v1", bt), testTree("v1", ht)
+			got := diffFields(base, head, bt, ht)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffFields() =")
+				for _, c := range got {
+					t.Errorf("  %+v", *c)
+				}
+				t.Errorf("want")
+				for _, c := range tt.want {
+					t.Errorf("  %+v", *c)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffFieldsOfEnumType(t *testing.T) {
+	enum := func(values string) *types.Type {
+		return &types.Type{
+			Name:                      types.Name{Package: "example.com/api/v1", Name: "Mode"},
+			Kind:                      types.Alias,
+			Underlying:                types.String,
+			SecondClosestCommentLines: []string{"+kubebuilder:validation:Enum=" + values},
+		}
+	}
+	bm, hm := enum("Fast;Slow"), enum("Fast")
+	bt := testStruct(testMember("Mode", bm, `json:"mode"`))
+	ht := testStruct(testMember("Mode", hm, `json:"mode"`))
+	base, head := testTree("v1", bt, bm), testTree("v1", ht, hm)
+
+	// the removed value is only reported on the type
+	if got := diffFields(base, head, bt, ht); len(got) != 0 {
+		t.Errorf("diffFields() = %+v, want no change", *got[0])
+	}
+	want := []*apiChange{{Change: changeRemoved, Subject: "enumValue", APIVersion: "widgets.example.com/v1", Type: "Mode", Old: "Slow"}}
+	if got := diffAPI(base, head); !reflect.DeepEqual(got, want) {
+		t.Errorf("diffAPI() = %d changes, want %+v", len(got), *want[0])
+	}
+}
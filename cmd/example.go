@@ -0,0 +1,447 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/gengo/types"
+	"sigs.k8s.io/yaml"
+)
+
+// examplePlaceholders are the example values of the types that serialize to
+// something else than their Go structure.
+var examplePlaceholders = map[string]exampleValue{
+	"k8s.io/apimachinery/pkg/api/resource.Quantity":                      {scalar: `"1"`},
+	"k8s.io/apimachinery/pkg/util/intstr.IntOrString":                    {scalar: `0`},
+	"k8s.io/apimachinery/pkg/apis/meta/v1.Time":                          {scalar: `"2006-01-02T15:04:05Z"`},
+	"k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime":                     {scalar: `"2006-01-02T15:04:05.000000Z"`},
+	"k8s.io/apimachinery/pkg/apis/meta/v1.Duration":                      {scalar: `1m0s`},
+	"k8s.io/apimachinery/pkg/runtime.RawExtension":                       {scalar: `{}`},
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1.JSON":      {scalar: `{}`},
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1.JSON": {scalar: `{}`},
+	// only the name is needed to create an object
+	"k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta": {block: []string{`name: ""`}},
+}
+
+// exampleValue is the YAML of a value, either a scalar (or flow collection)
+// written on the line of its key, or the lines of a nested block.
+type exampleValue struct {
+	scalar string
+	block  []string
+}
+
+func scalarValue(s string) exampleValue { return exampleValue{scalar: s} }
+
+// exampleBuilder builds example manifests from the member tree of the types.
+// The YAML is written line by line rather than marshaled, to comment out the
+// optional fields.
+type exampleBuilder struct {
+	r *renderer
+	// visiting are the structs being expanded, to stop at recursive types.
+	visiting map[*types.Type]bool
+}
+
+// kindExample returns an example manifest of the Kind t, with placeholder
+// values typed after the fields, or their default or first enum value where
+// known. Optional fields are commented out, and the status is left out.
+func (r *renderer) kindExample(t *types.Type) string {
+	b := &exampleBuilder{r: r, visiting: make(map[*types.Type]bool)}
+	lines := []string{
+		"apiVersion: " + r.typePkgMap[t].groupVersion(),
+		"kind: " + t.Name.Name,
+	}
+	// the status is written by the controller, not by users
+	lines = append(lines, b.structLines(t, map[string]bool{"apiVersion": true, "kind": true, "status": true})...)
+	return strings.Join(lines, "\n")
+}
+
+// structLines returns the fields of the struct t, with its inline members
+// flattened, except the skipped JSON names.
+func (b *exampleBuilder) structLines(t *types.Type, skipped map[string]bool) []string {
+	b.visiting[t] = true
+	defer delete(b.visiting, t)
+
+	var out []string
+	for _, f := range flattenMembers(t, b.r.config) {
+		m := f.Member
+		if skipped[fieldName(m)] {
+			continue
+		}
+		lines := entryLines(fieldName(m), b.memberValue(m))
+		// the API server requires the name of an object, even though the
+		// metadata is omitempty
+		if !isRequiredMember(m) && typeIdentifier(m.Type) != "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta" {
+			lines = commentOut(lines)
+		}
+		out = append(out, lines...)
+	}
+	return out
+}
+
+func (b *exampleBuilder) memberValue(m types.Member) exampleValue {
+	if d, _ := memberDefault(m); d != nil { // reported by warnInvalidMarkers
+		return yamlValue(d.Value)
+	}
+	if e := memberEnum(m, b.r.typePkgMap); e != nil {
+		if allowed := e.Allowed(); len(allowed) > 0 {
+			if t := m.Type; t.Kind == types.Slice {
+				return itemValue(enumExampleValue(t.Elem, allowed[0].Value))
+			}
+			return enumExampleValue(m.Type, allowed[0].Value)
+		}
+	}
+	return clampExample(b.typeValue(m.Type), memberValidation(m))
+}
+
+// typeValue returns the example value of the type t.
+func (b *exampleBuilder) typeValue(t *types.Type) exampleValue {
+	if t.Kind == types.Pointer {
+		return b.typeValue(t.Elem)
+	}
+	if v, ok := examplePlaceholders[typeIdentifier(t)]; ok && t.Kind != types.Slice && t.Kind != types.Map {
+		return v
+	}
+
+	switch t.Kind {
+	case types.Builtin:
+		return scalarValue(builtinExample(t))
+	case types.Alias:
+		if d, _ := typeDefault(t); d != nil {
+			return yamlValue(d.Value)
+		}
+		if e := typeEnum(t, b.r.typePkgMap[t]); e != nil {
+			if allowed := e.Allowed(); len(allowed) > 0 {
+				return enumExampleValue(t, allowed[0].Value)
+			}
+		}
+		return clampExample(b.typeValue(t.Underlying), typeValidation(t))
+	case types.Slice, types.Array:
+		if e := t.Elem; e.Kind == types.Builtin && e.Name.Name == "byte" {
+			return scalarValue(`""`)
+		}
+		return itemValue(b.typeValue(t.Elem))
+	case types.Map:
+		return exampleValue{block: entryLines("key", b.typeValue(t.Elem))}
+	case types.Struct:
+		if isLocalType(t, b.r.typePkgMap) && !b.visiting[t] {
+			if lines := b.structLines(t, nil); len(lines) > 0 {
+				return exampleValue{block: lines}
+			}
+		}
+	}
+	return scalarValue("{}")
+}
+
+func builtinExample(t *types.Type) string {
+	switch name := t.Name.Name; {
+	case name == "string":
+		return `""`
+	case name == "bool":
+		return "false"
+	case strings.HasPrefix(name, "float"):
+		return "0.0"
+	}
+	return "0"
+}
+
+// clampExample moves the number placeholder v within the Minimum and Maximum
+// of the validation c, so that the example validates.
+func clampExample(v exampleValue, c *fieldValidation) exampleValue {
+	n, err := strconv.ParseFloat(v.scalar, 64)
+	if v.block != nil || err != nil {
+		return v
+	}
+	min, hasMin := validationBound(c, "Minimum")
+	max, hasMax := validationBound(c, "Maximum")
+	minExclusive, _ := c.Get("ExclusiveMinimum")
+	maxExclusive, _ := c.Get("ExclusiveMaximum")
+	clamped := n
+	if hasMin && (clamped < min || clamped == min && minExclusive == "true") {
+		clamped = min
+		if minExclusive == "true" {
+			clamped++
+		}
+	}
+	if hasMax && (clamped > max || clamped == max && maxExclusive == "true") {
+		clamped = max
+		if maxExclusive == "true" {
+			clamped--
+		}
+	}
+	if hasMin && hasMax && (clamped < min || clamped > max) {
+		// a range narrower than the step of the exclusive bounds
+		clamped = (min + max) / 2
+	}
+	if clamped == n {
+		return v
+	}
+	return scalarValue(strconv.FormatFloat(clamped, 'f', -1, 64))
+}
+
+// validationBound returns the number argument of the marker of c.
+func validationBound(c *fieldValidation, marker string) (float64, bool) {
+	s, ok := c.Get(marker)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	return n, err == nil
+}
+
+// enumExampleValue returns the enum value v of the type t, typed after its
+// underlying type.
+func enumExampleValue(t *types.Type, v string) exampleValue {
+	u := t
+	for u.Kind == types.Alias || u.Kind == types.Pointer {
+		if u.Kind == types.Alias {
+			u = u.Underlying
+		} else {
+			u = u.Elem
+		}
+	}
+	if u.Kind == types.Builtin && u.Name.Name != "string" {
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(v), &value); err == nil {
+			return yamlValue(value)
+		}
+	}
+	return yamlValue(v)
+}
+
+// yamlValue marshals v, a decoded default or enum value.
+func yamlValue(v interface{}) exampleValue {
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return scalarValue("{}")
+	}
+	lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			return exampleValue{block: lines}
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			return exampleValue{block: lines}
+		}
+	}
+	return scalarValue(lines[0])
+}
+
+// entryLines returns the lines of the mapping entry key: value.
+func entryLines(key string, v exampleValue) []string {
+	if v.block == nil {
+		return []string{key + ": " + v.scalar}
+	}
+	return append([]string{key + ":"}, indentLines(v.block, "  ")...)
+}
+
+// itemValue returns a sequence with the single item v.
+func itemValue(v exampleValue) exampleValue {
+	if v.block == nil {
+		return exampleValue{block: []string{"- " + v.scalar}}
+	}
+	if strings.HasPrefix(v.block[0], "#") {
+		// the item starts with a commented out field
+		return exampleValue{block: append([]string{"-"}, indentLines(v.block, "  ")...)}
+	}
+	return exampleValue{block: append([]string{"- " + v.block[0]}, indentLines(v.block[1:], "  ")...)}
+}
+
+func indentLines(lines []string, indent string) []string {
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = indent + l
+	}
+	return out
+}
+
+// commentOut comments out the lines of an entry, uncommenting the nested
+// entries that already are.
+func commentOut(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		trimmed := strings.TrimLeft(l, " ")
+		indent := l[:len(l)-len(trimmed)]
+		out[i] = "# " + indent + strings.TrimPrefix(trimmed, "# ")
+	}
+	return out
+}
+
+// kindExamples returns an example manifest for every Kind, by path.
+func (r *renderer) kindExamples() map[string]string {
+	out := make(map[string]string)
+	for _, pkg := range r.pkgs {
+		for _, t := range visibleTypes(sortTypes(pkg.Types), r.config) {
+			if isExportedType(t) && !isListType(t) {
+				p := path.Join(pkg.apiGroup, pkg.apiVersion, strings.ToLower(t.Name.Name)+".yaml")
+				out[p] = r.kindExample(t) + "\n"
+			}
+		}
+	}
+	return out
+}
+
+// exampleMarker starts a block of YAML in the doc comment of a type or a
+// field, up to the next empty line or marker. Its optional argument is the
+// title of the example.
+const exampleMarker = "gencrdrefdocs:example"
+
+// example is a hand-written example, from the examplesDir or an
+// exampleMarker.
+type example struct {
+	Title string
+	// Field is the JSON name of the field whose doc comment holds the
+	// example, if any.
+	Field   string
+	Content string
+}
+
+var yamlDocumentSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// loadExamples reads the examples of the config examplesDir and of the
+// exampleMarkers, checking that they parse and that their apiVersion and
+// kind, if any, are of a documented Kind.
+func (r *renderer) loadExamples() error {
+	r.examples = make(map[*types.Type][]*example)
+	kinds := make(map[string]*types.Type)
+	for _, pkg := range r.pkgs {
+		for _, t := range visibleTypes(pkg.Types, r.config) {
+			if isExportedType(t) {
+				kinds[pkg.groupVersion()+"/"+t.Name.Name] = t
+			}
+		}
+	}
+	check := func(source, content string, requireKind bool) (*types.Type, error) {
+		if strings.TrimSpace(content) == "" {
+			return nil, errors.Errorf("example %s is empty", source)
+		}
+		// the example of a field may be a list or a scalar
+		var v interface{}
+		if err := yaml.Unmarshal([]byte(content), &v); err != nil {
+			return nil, errors.Wrapf(err, "example %s does not parse", source)
+		}
+		obj, _ := v.(map[string]interface{})
+		apiVersion, _ := obj["apiVersion"].(string)
+		kind, _ := obj["kind"].(string)
+		if apiVersion == "" && kind == "" && !requireKind {
+			return nil, nil
+		}
+		t, ok := kinds[apiVersion+"/"+kind]
+		if !ok {
+			return nil, errors.Errorf("example %s: apiVersion %q and kind %q are not of a documented Kind", source, apiVersion, kind)
+		}
+		return t, nil
+	}
+
+	if dir := r.config.ExamplesDir; dir != "" {
+		var files []string
+		err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if ext := filepath.Ext(p); !info.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to read examplesDir")
+		}
+		sort.Strings(files)
+		for _, f := range files {
+			b, err := ioutil.ReadFile(f)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read example %s", f)
+			}
+			rel, _ := filepath.Rel(dir, f)
+			for _, doc := range yamlDocumentSeparator.Split(string(b), -1) {
+				if doc = strings.TrimSpace(doc); doc == "" {
+					continue
+				}
+				t, err := check(f, doc, true)
+				if err != nil {
+					return err
+				}
+				r.examples[t] = append(r.examples[t], &example{Title: filepath.ToSlash(rel), Content: doc})
+			}
+		}
+	}
+
+	for _, pkg := range r.pkgs {
+		for _, t := range sortTypes(pkg.Types) {
+			for _, e := range commentExamples(typeCommentLines(t)) {
+				source := "of type " + t.Name.String()
+				kind, err := check(source, e.Content, false)
+				if err != nil {
+					return err
+				}
+				if kind != nil && kind != t {
+					return errors.Errorf("example %s: apiVersion and kind are of %s", source, kind.Name)
+				}
+				r.examples[t] = append(r.examples[t], e)
+			}
+			for _, m := range t.Members {
+				for _, e := range commentExamples(m.CommentLines) {
+					e.Field = fieldName(m)
+					if _, err := check("of field "+t.Name.String()+"."+e.Field, e.Content, false); err != nil {
+						return err
+					}
+					r.examples[t] = append(r.examples[t], e)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// commentExamples returns the exampleMarker blocks of a doc comment.
+func commentExamples(lines []string) []*example {
+	var out []*example
+	for i := 0; i < len(lines); i++ {
+		title, ok := lastMarkerValue(lines[i:i+1], exampleMarker)
+		if !ok {
+			continue
+		}
+		end := exampleBlockEnd(lines, i)
+		out = append(out, &example{
+			Title:   unquoteMarkerValue(title),
+			Content: dedent(lines[i+1 : end]),
+		})
+		i = end - 1
+	}
+	return out
+}
+
+// exampleBlockEnd returns the index of the line after the exampleMarker
+// block starting at the marker lines[start].
+func exampleBlockEnd(lines []string, start int) int {
+	for i := start + 1; i < len(lines); i++ {
+		if l := strings.TrimSpace(lines[i]); l == "" || strings.HasPrefix(l, "+") {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+// dedent removes the indentation common to lines and joins them.
+func dedent(lines []string) string {
+	indent := -1
+	for _, l := range lines {
+		if n := len(l) - len(strings.TrimLeft(l, " \t")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = l[indent:]
+	}
+	return strings.Join(out, "\n")
+}
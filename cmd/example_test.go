@@ -0,0 +1,159 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/types"
+)
+
+func TestLoadExamples(t *testing.T) {
+	tests := []struct {
+		name    string
+		group   string
+		comment []string
+		// wantErr is a substring of the expected error, "" for none
+		wantErr string
+	}{
+		{
+			name:    "kind example",
+			group:   "widgets.example.com",
+			comment: []string{"+gencrdrefdocs:example", "apiVersion: widgets.example.com/v1", "kind: Widget"},
+		},
+		{
+			name:    "core group kind example",
+			comment: []string{"+gencrdrefdocs:example", "apiVersion: v1", "kind: Widget"},
+		},
+		{
+			name:    "example without a kind",
+			group:   "widgets.example.com",
+			comment: []string{"+gencrdrefdocs:example", "spec: {}"},
+		},
+		{
+			name:    "example of another version",
+			group:   "widgets.example.com",
+			comment: []string{"+gencrdrefdocs:example", "apiVersion: widgets.example.com/v2", "kind: Widget"},
+			wantErr: "not of a documented Kind",
+		},
+		{
+			name:    "empty example",
+			group:   "widgets.example.com",
+			comment: []string{"+gencrdrefdocs:example", "", "Widget is a widget."},
+			wantErr: "is empty",
+		},
+		{
+			name:    "example not parsing",
+			group:   "widgets.example.com",
+			comment: []string{"+gencrdrefdocs:example", "spec: [}"},
+			wantErr: "does not parse",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind := testKind("Widget")
+			kind.CommentLines = tt.comment
+			pkg := &apiPackage{apiGroup: tt.group, apiVersion: "v1", Types: []*types.Type{kind}}
+			r := newRenderer([]*apiPackage{pkg}, GeneratorConfig{}, formatMarkdown)
+			err := r.loadExamples()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadExamples() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadExamples() = %v", err)
+			}
+			if n := len(r.examples[kind]); n != 1 {
+				t.Errorf("got %d examples of the Kind, want 1", n)
+			}
+		})
+	}
+}
+
+func TestClampExample(t *testing.T) {
+	validation := func(markers ...string) *fieldValidation {
+		return markerValidation(markers)
+	}
+	tests := []struct {
+		name  string
+		value exampleValue
+		c     *fieldValidation
+		want  string
+	}{
+		{"no validation", scalarValue("0"), nil, "0"},
+		{"within the range", scalarValue("0"), validation("+kubebuilder:validation:Minimum=-1", "+kubebuilder:validation:Maximum=1"), "0"},
+		{"below the minimum", scalarValue("0"), validation("+kubebuilder:validation:Minimum=1"), "1"},
+		{"above the maximum", scalarValue("0"), validation("+kubebuilder:validation:Maximum=-5"), "-5"},
+		{"exclusive minimum", scalarValue("0"), validation("+kubebuilder:validation:Minimum=0", "+kubebuilder:validation:ExclusiveMinimum=true"), "1"},
+		{"exclusive maximum", scalarValue("0"), validation("+kubebuilder:validation:Maximum=0", "+kubebuilder:validation:ExclusiveMaximum=true"), "-1"},
+		{"float", scalarValue("0.0"), validation("+kubebuilder:validation:Minimum=0.5"), "0.5"},
+		{
+			"range narrower than the step",
+			scalarValue("0"),
+			validation("+kubebuilder:validation:Minimum=1", "+kubebuilder:validation:Maximum=1.5", "+kubebuilder:validation:ExclusiveMinimum=true", "+kubebuilder:validation:ExclusiveMaximum=true"),
+			"1.25",
+		},
+		{"invalid bound", scalarValue("0"), validation("+kubebuilder:validation:Minimum=one"), "0"},
+		{"string", scalarValue(`""`), validation("+kubebuilder:validation:Minimum=1"), `""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clampExample(tt.value, tt.c); got.scalar != tt.want {
+				t.Errorf("clampExample() = %q, want %q", got.scalar, tt.want)
+			}
+		})
+	}
+}
+
+func TestKindExample(t *testing.T) {
+	node := &types.Type{Name: types.Name{Package: "example.com/api/v1", Name: "Node"}, Kind: types.Struct}
+	node.Members = []types.Member{
+		testMember("Next", &types.Type{Kind: types.Pointer, Elem: node}, `json:"next,omitempty"`, "+optional"),
+		testMember("Value", types.String, `json:"value"`),
+	}
+	mode := &types.Type{
+		Name:         types.Name{Package: "example.com/api/v1", Name: "Mode"},
+		Kind:         types.Alias,
+		Underlying:   types.String,
+		CommentLines: []string{"+kubebuilder:validation:Enum=Fast;Slow"},
+	}
+	spec := testStruct(
+		testMember("Replicas", types.Int32, `json:"replicas"`, "+kubebuilder:validation:Minimum=1"),
+		testMember("Mode", mode, `json:"mode,omitempty"`, "+optional"),
+		testMember("Size", types.Int32, `json:"size,omitempty"`, "+optional", "+kubebuilder:default=3"),
+		testMember("Root", node, `json:"root"`),
+	)
+	status := &types.Type{Name: types.Name{Package: "example.com/api/v1", Name: "WidgetStatus"}, Kind: types.Struct}
+	kind := testKind("Widget")
+	kind.Members = []types.Member{
+		testMember("Spec", spec, `json:"spec"`),
+		testMember("Status", status, `json:"status,omitempty"`),
+	}
+
+	tests := []struct {
+		group string
+		want  string
+	}{
+		{"widgets.example.com", "apiVersion: widgets.example.com/v1\n"},
+		{"", "apiVersion: v1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			pkg := &apiPackage{apiGroup: tt.group, apiVersion: "v1", Types: []*types.Type{kind, spec, node, mode, status}}
+			r := newRenderer([]*apiPackage{pkg}, GeneratorConfig{}, formatMarkdown)
+			want := tt.want +
+				"kind: Widget\n" +
+				"spec:\n" +
+				"  replicas: 1\n" +
+				"  # mode: Fast\n" +
+				"  # size: 3\n" +
+				"  root:\n" +
+				"    # next: {}\n" +
+				"    value: \"\""
+			if got := r.kindExample(kind); got != want {
+				t.Errorf("kindExample() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+)
+
+// git runs a git command in dir and returns its trimmed output.
+func git(dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// apiCheckout is a revision of the git repository holding -api-dir, checked
+// out into a temporary worktree. The worktree is placed in a GOPATH of its
+// own, at the import path of the repository, for gengo to parse it.
+type apiCheckout struct {
+	repo     string
+	gopath   string
+	worktree string
+	// importPath is the import path of -api-dir in the worktree.
+	importPath string
+}
+
+// checkoutAPIDir checks rev of the git repository holding apiDir out into
+// a temporary worktree. It must be removed once done with.
+func checkoutAPIDir(apiDir, rev string) (*apiCheckout, error) {
+	dir, err := filepath.Abs(apiDir)
+	if err != nil {
+		return nil, err
+	}
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		return nil, err
+	}
+	repo, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s is not in a git repository", apiDir)
+	}
+	rel, err := filepath.Rel(repo, dir)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := build.Default.ImportDir(dir, build.FindOnly)
+	if err != nil || build.IsLocalImport(pkg.ImportPath) || strings.HasPrefix(pkg.ImportPath, "_") {
+		return nil, errors.Errorf("%s must be in GOPATH to check revisions out", apiDir)
+	}
+	repoImportPath := pkg.ImportPath
+	if rel = filepath.ToSlash(rel); rel != "." {
+		if !strings.HasSuffix(repoImportPath, "/"+rel) {
+			return nil, errors.Errorf("cannot find the import path of the repository of %s", apiDir)
+		}
+		repoImportPath = strings.TrimSuffix(repoImportPath, "/"+rel)
+	}
+
+	gopath, err := ioutil.TempDir("", "gen-crd-api-reference-docs-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a temporary GOPATH")
+	}
+	c := &apiCheckout{
+		repo:       repo,
+		gopath:     gopath,
+		worktree:   filepath.Join(gopath, "src", filepath.FromSlash(repoImportPath)),
+		importPath: pkg.ImportPath,
+	}
+	if err := os.MkdirAll(filepath.Dir(c.worktree), 0755); err != nil {
+		os.RemoveAll(gopath)
+		return nil, err
+	}
+	klog.Infof("checking %s out into %s", rev, c.worktree)
+	if _, err := git(repo, "worktree", "add", "--detach", c.worktree, rev); err != nil {
+		os.RemoveAll(gopath)
+		return nil, err
+	}
+	return c, nil
+}
+
+// apiDir returns the path of -api-dir in the worktree.
+func (c *apiCheckout) apiDir() string {
+	return filepath.Join(c.gopath, "src", filepath.FromSlash(c.importPath))
+}
+
+// worktreePath returns the path in the worktree of the file or directory p
+// of the repository, and false if p is outside of the repository.
+func (c *apiCheckout) worktreePath(p string) (string, bool, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", false, err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	rel, err := filepath.Rel(c.repo, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false, nil
+	}
+	return filepath.Join(c.worktree, rel), true, nil
+}
+
+// parse parses the API packages of the worktree.
+func (c *apiCheckout) parse() ([]*apiPackage, error) {
+	// gengo resolves packages with the GOPATH of the default build context
+	defer func(gopath string) { build.Default.GOPATH = gopath }(build.Default.GOPATH)
+	build.Default.GOPATH = strings.Join([]string{c.gopath, build.Default.GOPATH}, string(filepath.ListSeparator))
+
+	klog.Infof("parsing go packages in %s", c.importPath)
+	pkgs, err := ParseAPIPackages(c.importPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) == 0 {
+		return nil, errors.Errorf("no API packages found in %s", c.apiDir())
+	}
+	return combineAPIPackages(pkgs)
+}
+
+// remove removes the worktree.
+func (c *apiCheckout) remove() {
+	if _, err := git(c.repo, "worktree", "remove", "--force", c.worktree); err != nil {
+		klog.Warningf("failed to remove the worktree: %v", err)
+	}
+	os.RemoveAll(c.gopath)
+}
@@ -4,30 +4,65 @@ import (
 	"bytes"
 	"encoding/json"
 	"flag"
-	"fmt"
 	"io/ioutil"
-	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
-	"time"
+	"strings"
+	texttemplate "text/template"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog"
 )
 
 var (
-	flAPIDir      = flag.String("api-dir", "", "api directory (or import path), point this to pkg/apis")
-	flConfig      = flag.String("config", "config/config.json", "path to config file")
-	flTemplateDir = flag.String("template-dir", "templates/html", "path to template/ dir")
+	flAPIDir       = flag.String("api-dir", "", "api directory (or import path), point this to pkg/apis")
+	flConfig       = flag.String("config", "config/config.json", "path to config file")
+	flTemplateDirs = stringsVar("template-dir", "template directory whose .tpl files override the built-in templates of the -format, can be repeated or comma-separated (the first directory takes precedence)")
+	flFormat       = flag.String("format", "", "output format: html, markdown, asciidoc, json, jsonschema, yaml or openapi (defaults to the name of the first -template-dir directory named after one, or html)")
 
-	flHTTPAddr = flag.String("http-addr", "", "start an HTTP server on specified addr to view the result (e.g. :8080)")
-	flOutFile  = flag.String("out-file", "", "path to output file to save the result")
+	flHTTPAddr  = flag.String("http-addr", "", "start an HTTP server on specified addr to view the result (e.g. :8080)")
+	flOutFile   = flag.String("out-file", "", "path to output file to save the result")
+	flOutDir    = flag.String("out-dir", "", "path to output directory to save the result as an index page and a page per API group version")
+	flKindPages = flag.Bool("kind-pages", false, "with -out-dir, also give each Kind its own page")
+
+	flBaseAPIDir = flag.String("base-api-dir", "", "with the diff and check-compat commands, api directory of the base version to compare -api-dir with")
+	flBaseRev    = flag.String("base-rev", "", "with the diff and check-compat commands, git revision of the repository of -api-dir to compare, instead of -base-api-dir")
+	flHeadRev    = flag.String("head-rev", "", "with the diff and check-compat commands, git revision to compare -base-rev with, instead of the -api-dir working tree")
+	flTags       = flag.String("tags", "*", "with the versioned-docs command, pattern of the git tags of the repository of -api-dir to generate the documentation of")
+	flPolicy     = flag.String("policy", "", "with the check-compat command, path to the policy file allowing incompatible changes")
 )
 
+// commands can be given as the first argument to do something else than
+// generating the documentation. They accept the same flags.
+var commands = map[string]func(){
+	"templates":      listTemplates,
+	"diff":           diffCommand,
+	"check-compat":   checkCompatCommand,
+	"versioned-docs": versionedDocsCommand,
+}
+
+// stringsFlag is a flag that can be repeated, or given a comma-separated list.
+type stringsFlag []string
+
+func stringsVar(name, usage string) *stringsFlag {
+	var s stringsFlag
+	flag.Var(&s, name, usage)
+	return &s
+}
+
+func (s *stringsFlag) String() string { return strings.Join(*s, ",") }
+
+func (s *stringsFlag) Set(v string) error {
+	for _, p := range strings.Split(v, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			*s = append(*s, p)
+		}
+	}
+	return nil
+}
+
 func initFlags() {
-	klog.InitFlags(nil)
-	flag.Set("alsologtostderr", "true") // for klog
 	flag.Parse()
 
 	if *flConfig == "" {
@@ -36,14 +71,26 @@ func initFlags() {
 	if *flAPIDir == "" {
 		panic("-api-dir not specified")
 	}
-	if *flHTTPAddr == "" && *flOutFile == "" {
-		panic("-out-file or -http-addr must be specified")
+	outputs := 0
+	for _, v := range []string{*flHTTPAddr, *flOutFile, *flOutDir} {
+		if v != "" {
+			outputs++
+		}
+	}
+	if outputs == 0 {
+		panic("-out-file, -out-dir or -http-addr must be specified")
+	}
+	if outputs > 1 {
+		panic("only one of -out-file, -out-dir or -http-addr can be specified")
+	}
+	if *flKindPages && *flOutDir == "" {
+		panic("-kind-pages requires -out-dir")
 	}
-	if *flHTTPAddr != "" && *flOutFile != "" {
-		panic("only -out-file or -http-addr can be specified")
+	if (outputFormat() == formatJSONSchema || outputFormat() == formatYAML) && *flOutDir == "" {
+		panic("-format " + outputFormat() + " requires -out-dir")
 	}
 
-	if err := isDirExists(*flTemplateDir); err != nil {
+	if err := validateTemplateFlags(); err != nil {
 		panic(err)
 	}
 
@@ -52,6 +99,41 @@ func initFlags() {
 	}
 }
 
+// validateTemplateFlags checks the flags selecting the templates to render.
+func validateTemplateFlags() error {
+	if !containsString(formats, outputFormat()) {
+		return errors.Errorf("unknown -format %q, must be one of %v", *flFormat, formats)
+	}
+	if len(*flTemplateDirs) > 0 && !containsString(templateFormats, outputFormat()) {
+		return errors.Errorf("-template-dir cannot be used with -format %s", outputFormat())
+	}
+	for _, dir := range *flTemplateDirs {
+		if err := isDirExists(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputFormat returns the -format to render, inferring it from the name of
+// the template directories if it is not specified.
+func outputFormat() string {
+	if *flFormat != "" {
+		return *flFormat
+	}
+	for _, dir := range *flTemplateDirs {
+		if dir := filepath.Base(filepath.Clean(dir)); containsString(templateFormats, dir) {
+			return dir
+		}
+	}
+	return formatHTML
+}
+
+var (
+	leadingSpaceRegex = regexp.MustCompile(`(?m)^\s+`)
+	preRegex          = regexp.MustCompile(`(?s)<pre[ >].*?</pre>`)
+)
+
 func isDirExists(dir string) error {
 	path, err := filepath.Abs(dir)
 	if err != nil {
@@ -66,56 +148,141 @@ func isDirExists(dir string) error {
 }
 
 func readConfigFromFile() GeneratorConfig {
-	f, err := os.Open(*flConfig)
+	config, err := loadConfig(*flConfig)
 	if err != nil {
-		klog.Fatalf("failed to open config file: %+v", err)
+		klog.Fatalf("%+v", err)
 	}
+	return config
+}
+
+// loadConfig reads and validates the config file at path.
+func loadConfig(path string) (GeneratorConfig, error) {
+	var config GeneratorConfig
+	f, err := os.Open(path)
+	if err != nil {
+		return config, errors.Wrap(err, "failed to open config file")
+	}
+	defer f.Close()
+
 	d := json.NewDecoder(f)
 	d.DisallowUnknownFields()
-	var config GeneratorConfig
 	if err := d.Decode(&config); err != nil {
-		klog.Fatalf("failed to parse config file: %+v", err)
+		return config, errors.Wrap(err, "failed to parse config file")
 	}
 
-	return config
+	// patterns are compiled lazily during rendering, where a bad one would
+	// otherwise panic
+	for _, pattern := range config.HideTypePatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return config, errors.Wrapf(err, "invalid hideTypePatterns entry %q", pattern)
+		}
+	}
+	if config.Expand != nil {
+		if err := config.Expand.compile(); err != nil {
+			return config, err
+		}
+		if !containsString(templateFormats, outputFormat()) {
+			klog.Warningf("expand is ignored by -format %s", outputFormat())
+		}
+	}
+	if _, err := texttemplate.New("").Parse(config.SourceLinkTemplate); err != nil {
+		return config, errors.Wrap(err, "invalid sourceLinkTemplate")
+	}
+	for _, v := range config.ExternalPackages {
+		if _, err := regexp.Compile(v.TypeMatchPrefix); err != nil {
+			return config, errors.Wrapf(err, "invalid typeMatchPrefix %q", v.TypeMatchPrefix)
+		}
+	}
+	if config.SiteGenerator != nil {
+		if err := config.SiteGenerator.validate(); err != nil {
+			return config, err
+		}
+	}
+	return config, nil
 }
 
 func main() {
 	defer klog.Flush()
 
+	klog.InitFlags(nil)
+	flag.Set("alsologtostderr", "true") // for klog
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			flag.CommandLine.Parse(os.Args[2:])
+			cmd()
+			return
+		}
+	}
+
 	initFlags()
 
 	config := readConfigFromFile()
 
 	klog.V(3).Infof("log level 4+")
 
-	klog.Infof("parsing go packages in directory %s", *flAPIDir)
-
-	pkgs, err := ParseAPIPackages(*flAPIDir)
-	if err != nil {
-		klog.Fatal(err)
+	if *flHTTPAddr != "" {
+		serveWithLiveReload()
+		return
 	}
-	if len(pkgs) == 0 {
-		klog.Fatalf("no API packages found in %s", *flAPIDir)
+
+	if *flOutDir != "" {
+		pages, err := buildSite(config)
+		if err != nil {
+			klog.Fatalf("failed: %+v", err)
+		}
+		if err := outputToDir(*flOutDir, pages); err != nil {
+			klog.Fatalf("%+v", err)
+		}
+		return
 	}
 
-	apiPackages, err := combineAPIPackages(pkgs)
+	s, err := buildDoc(config)
 	if err != nil {
-		klog.Fatal(err)
+		klog.Fatalf("failed: %+v", err)
 	}
+	outputToFile(s)
+}
 
-	s, err := generateDoc(apiPackages, config)
+// buildDoc runs the whole parse and render pipeline for -api-dir. Every
+// failure is returned rather than being fatal, so that the live server can
+// report it and keep running.
+func buildDoc(config GeneratorConfig) (string, error) {
+	apiPackages, err := parseAPIDir(*flAPIDir)
 	if err != nil {
-		klog.Fatalf("failed: %+v", err)
+		return "", err
 	}
+	prov, err := newProvenance(*flAPIDir, config)
+	if err != nil {
+		return "", err
+	}
+	return generateDoc(apiPackages, config, prov)
+}
 
-	if *flOutFile != "" {
-		outputToFile(s)
+// buildSite is the -out-dir counterpart of buildDoc, returning the content of
+// every page by path.
+func buildSite(config GeneratorConfig) (map[string]string, error) {
+	apiPackages, err := parseAPIDir(*flAPIDir)
+	if err != nil {
+		return nil, err
+	}
+	prov, err := newProvenance(*flAPIDir, config)
+	if err != nil {
+		return nil, err
 	}
+	return generateSite(apiPackages, config, prov)
+}
 
-	if *flHTTPAddr != "" {
+func parseAPIDir(dir string) ([]*apiPackage, error) {
+	klog.Infof("parsing go packages in directory %s", dir)
 
+	pkgs, err := ParseAPIPackages(dir)
+	if err != nil {
+		return nil, err
 	}
+	if len(pkgs) == 0 {
+		return nil, errors.Errorf("no API packages found in %s", dir)
+	}
+	return combineAPIPackages(pkgs)
 }
 
 func outputToFile(s string) {
@@ -132,32 +299,65 @@ func outputToFile(s string) {
 	klog.Infof("written to %s", *flOutFile)
 }
 
-func serverWithHttpServer(s string) {
-	h := func(w http.ResponseWriter, r *http.Request) {
-		now := time.Now()
-		defer func() { klog.Infof("request took %v", time.Since(now)) }()
-
-		if _, err := fmt.Fprint(w, s); err != nil {
-			klog.Warningf("response write error: %v", err)
+// outputToDir writes the pages to their path relative to dir.
+func outputToDir(dir string, pages map[string]string) error {
+	for path, s := range pages {
+		file := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return errors.Wrapf(err, "failed to create dir %s", filepath.Dir(file))
+		}
+		if err := ioutil.WriteFile(file, []byte(s), 0644); err != nil {
+			return errors.Wrapf(err, "failed to write to %s", file)
 		}
 	}
-	http.HandleFunc("/", h)
-	klog.Infof("server listening at %s", *flHTTPAddr)
-	klog.Fatal(http.ListenAndServe(*flHTTPAddr, nil))
+
+	klog.Infof("written %d files to %s", len(pages), dir)
+	return nil
 }
 
-func generateDoc(apiPackages []*apiPackage, config GeneratorConfig) (string, error) {
+func generateDoc(apiPackages []*apiPackage, config GeneratorConfig, prov *provenance) (string, error) {
 	var b bytes.Buffer
-	err := Render(&b, apiPackages, config)
+	err := Render(&b, apiPackages, config, prov)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to render the result")
 	}
 
-	if config.PreserveTrailingWhitespace {
-		return b.String(), nil
+	return postProcess(b.String(), config), nil
+}
+
+func generateSite(apiPackages []*apiPackage, config GeneratorConfig, prov *provenance) (map[string]string, error) {
+	pages, err := RenderSite(apiPackages, config, *flKindPages, prov)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render the result")
 	}
+	for path, s := range pages {
+		pages[path] = postProcess(s, config)
+	}
+	return pages, nil
+}
 
-	// remove trailing whitespace from each html line for markdown renderers
-	s := regexp.MustCompile(`(?m)^\s+`).ReplaceAllString(b.String(), "")
-	return s, nil
+// postProcess cleans up the whitespace of a rendered page.
+func postProcess(s string, config GeneratorConfig) string {
+	if config.PreserveTrailingWhitespace || !containsString(templateFormats, outputFormat()) {
+		return s
+	}
+
+	if outputFormat() != formatHTML {
+		return tidyText(s)
+	}
+
+	// remove trailing whitespace from each html line for markdown renderers,
+	// except in preformatted blocks
+	var b strings.Builder
+	for {
+		loc := preRegex.FindStringIndex(s)
+		if loc == nil {
+			break
+		}
+		b.WriteString(leadingSpaceRegex.ReplaceAllString(s[:loc[0]], ""))
+		b.WriteString(s[loc[0]:loc[1]])
+		s = s[loc[1]:]
+	}
+	b.WriteString(leadingSpaceRegex.ReplaceAllString(s, ""))
+	return b.String()
 }
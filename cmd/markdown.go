@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// markdownEscaper escapes the characters that have a meaning in inline
+// Markdown text.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"|", `\|`,
+)
+
+// markdownEscape escapes s so that it is displayed verbatim in Markdown.
+func markdownEscape(s string) string { return markdownEscaper.Replace(s) }
+
+// markdownCode renders s as an inline code span that is also safe to use in
+// a table cell.
+func markdownCode(s string) string {
+	s = strings.Replace(s, "|", `\|`, -1)
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
+
+// markdownLink renders a link to url, or just the text if url is empty.
+func markdownLink(text, url string) string {
+	if url == "" {
+		return markdownEscape(text)
+	}
+	return "[" + markdownEscape(text) + "](" + url + ")"
+}
+
+// markdownCell turns a block of Markdown into a single line that fits in a
+// pipe table cell.
+func markdownCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return escapeUnescapedPipes(s)
+}
+
+func escapeUnescapedPipes(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '|' && (i == 0 || s[i-1] != '\\') {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// renderMarkdownComments is the Markdown counterpart of renderComments. Godoc
+// comments are already close to Markdown, so the text is kept as-is except
+// for angle brackets outside of code spans, which Markdown renderers would
+// otherwise treat as HTML tags (e.g. "http://<service>"), and braces, which
+// MDX renderers such as Docusaurus treat as expressions. If markdown is
+// false, the comments are plain text and all Markdown syntax is escaped.
+func renderMarkdownComments(s []string, markdown bool) string {
+	s = filterCommentTags(s)
+	doc := strings.TrimSpace(strings.Join(s, "\n"))
+	if !markdown {
+		return markdownEscape(doc)
+	}
+
+	var b strings.Builder
+	inCode := false
+	for _, r := range doc {
+		switch {
+		case r == '`':
+			inCode = !inCode
+		case !inCode && strings.ContainsRune("<>{}", r):
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var (
+	// markdownHeadingRegex matches an ATX heading and its optional {#id}
+	// attribute.
+	markdownHeadingRegex = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t]+(.*?))??(?:[ \t]+\{#([^}\s]+)\})?[ \t]*$`)
+	// markdownFenceRegex matches the delimiter of a fenced code block.
+	markdownFenceRegex = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+	// markdownAnchorLinkRegex matches the anchor of a link, which the
+	// relref shortcode of Hugo quotes.
+	markdownAnchorLinkRegex = regexp.MustCompile(`#([a-z0-9_-]+)([)"])`)
+)
+
+// githubHeadingAnchors replaces the {#id} attributes of the headings of the
+// Markdown pages, which GitHub does not support, with the anchors GitHub
+// derives from the heading text, and rewrites the links to them. Pages are
+// keyed by path.
+func githubHeadingAnchors(pages map[string]string) {
+	anchors := make(map[string]string)
+	for path, s := range pages {
+		lines := strings.Split(s, "\n")
+		slugs := make(map[string]int)
+		fence := ""
+		for i, l := range lines {
+			if m := markdownFenceRegex.FindStringSubmatch(l); m != nil {
+				switch {
+				case fence == "":
+					fence = m[1]
+				case strings.HasPrefix(m[1], fence):
+					fence = ""
+				}
+				continue
+			}
+			if fence != "" {
+				continue
+			}
+			m := markdownHeadingRegex.FindStringSubmatchIndex(l)
+			if m == nil {
+				continue
+			}
+			var text string
+			if m[2] >= 0 {
+				text = l[m[2]:m[3]]
+			}
+			slug := githubSlug(text, slugs)
+			if m[4] >= 0 {
+				anchors[l[m[4]:m[5]]] = slug
+				// drop the attribute and the space before it
+				lines[i] = strings.TrimRight(l[:m[3]], " \t")
+			}
+		}
+		pages[path] = strings.Join(lines, "\n")
+	}
+
+	for path, s := range pages {
+		pages[path] = markdownAnchorLinkRegex.ReplaceAllStringFunc(s, func(link string) string {
+			m := markdownAnchorLinkRegex.FindStringSubmatch(link)
+			if slug, ok := anchors[m[1]]; ok {
+				return "#" + slug + m[2]
+			}
+			return link
+		})
+	}
+}
+
+// githubSlug returns the anchor GitHub generates for a heading of Markdown
+// text, like github-slugger: the lowercased text without punctuation, with
+// spaces replaced by hyphens. seen counts the anchors of the page so far, to
+// number the duplicates.
+func githubSlug(text string, seen map[string]int) string {
+	text = markdownLinkRegex.ReplaceAllString(text, "$1")
+	var b strings.Builder
+	runes := []rune(strings.ToLower(text))
+	inCode := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && !inCode && i+1 < len(runes):
+			// escaped characters are kept as text
+			i++
+			r = runes[i]
+		case r == '`':
+			inCode = !inCode
+			continue
+		case r == '_' && !inCode && !(i > 0 && isWordRune(runes[i-1]) && i+1 < len(runes) && isWordRune(runes[i+1])):
+			// emphasis, unlike the underscores within words
+			continue
+		}
+		switch {
+		case r == ' ':
+			b.WriteByte('-')
+		case r == '-', r == '_', isWordRune(r), unicode.IsMark(r):
+			b.WriteRune(r)
+		}
+	}
+	slug := b.String()
+	out := slug
+	for {
+		if _, ok := seen[out]; !ok {
+			break
+		}
+		seen[slug]++
+		out = fmt.Sprintf("%s-%d", slug, seen[slug])
+	}
+	seen[out] = 0
+	return out
+}
+
+func isWordRune(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+func TestGithubHeadingAnchors(t *testing.T) {
+	pages := map[string]string{
+		"index.md": "# API Reference\n" +
+			"\n" +
+			"- [Widget](v1.md#widgets-example-com-v1-widget)\n" +
+			"- [Spec](#widgets-example-com-v1-widgetspec) [source](https://example.com/types.go#L12)\n",
+		"v1.md": "## widgets.example.com/v1 {#widgets-example-com-v1}\n" +
+			"\n" +
+			"### Widget `v1` _deprecated_ {#widgets-example-com-v1-widget}\n" +
+			"\n" +
+			"```yaml\n" +
+			"# not a heading {#nope}\n" +
+			"```\n" +
+			"\n" +
+			"### WidgetSpec {#widgets-example-com-v1beta1-widgetspec}\n" +
+			"\n" +
+			"### WidgetSpec {#widgets-example-com-v1-widgetspec}\n" +
+			"\n" +
+			"### The `max_size` \\_field\\_ {#field}\n" +
+			"\n" +
+			"See [Widget](#widgets-example-com-v1-widget) and {{< relref \"v1.md#field\" >}}.\n",
+	}
+	githubHeadingAnchors(pages)
+
+	want := map[string]string{
+		"index.md": "# API Reference\n" +
+			"\n" +
+			"- [Widget](v1.md#widget-v1-deprecated)\n" +
+			"- [Spec](#widgetspec-1) [source](https://example.com/types.go#L12)\n",
+		"v1.md": "## widgets.example.com/v1\n" +
+			"\n" +
+			"### Widget `v1` _deprecated_\n" +
+			"\n" +
+			"```yaml\n" +
+			"# not a heading {#nope}\n" +
+			"```\n" +
+			"\n" +
+			"### WidgetSpec\n" +
+			"\n" +
+			"### WidgetSpec\n" +
+			"\n" +
+			"### The `max_size` \\_field\\_\n" +
+			"\n" +
+			"See [Widget](#widget-v1-deprecated) and {{< relref \"v1.md#the-max_size-_field_\" >}}.\n",
+	}
+	for path, s := range want {
+		if pages[path] != s {
+			t.Errorf("%s =\n%s\nwant\n%s", path, pages[path], s)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"k8s.io/gengo/types"
+)
+
+// markerValues returns the argument of every "+<name>" marker in lines, in
+// order. Both the "+name=value" and "+name:args" forms are recognized; a
+// marker without an argument yields an empty string. Markers that merely
+// start with name (e.g. "+kubebuilder:validation:MinLength" when looking for
+// "kubebuilder:validation:Min") do not match.
+func markerValues(lines []string, name string) []string {
+	var out []string
+	prefix := "+" + name
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		rest := line[len(prefix):]
+		switch {
+		case rest == "":
+			out = append(out, "")
+		case rest[0] == '=' || rest[0] == ':':
+			out = append(out, rest[1:])
+		}
+	}
+	return out
+}
+
+// hasMarker reports whether lines contain the "+<name>" marker.
+func hasMarker(lines []string, name string) bool {
+	return len(markerValues(lines, name)) > 0
+}
+
+// lastMarkerValue returns the argument of the last "+<name>" marker in lines,
+// which is the one that takes effect when a marker is repeated.
+func lastMarkerValue(lines []string, name string) (string, bool) {
+	v := markerValues(lines, name)
+	if len(v) == 0 {
+		return "", false
+	}
+	return v[len(v)-1], true
+}
+
+// unquoteMarkerValue strips the double quotes or backticks markers commonly
+// wrap their arguments in.
+func unquoteMarkerValue(v string) string {
+	v = strings.TrimSpace(v)
+	if len(v) >= 2 && v[0] == '`' && v[len(v)-1] == '`' {
+		return v[1 : len(v)-1]
+	}
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		if s, err := strconv.Unquote(v); err == nil {
+			return s
+		}
+	}
+	return v
+}
+
+// typeCommentLines returns all the comment lines markers can be placed in for
+// a type declaration: the doc comment, and the comment block separated from
+// it by an empty line.
+func typeCommentLines(t *types.Type) []string {
+	out := make([]string, 0, len(t.SecondClosestCommentLines)+len(t.CommentLines))
+	out = append(out, t.SecondClosestCommentLines...)
+	return append(out, t.CommentLines...)
+}
+
+// namedMemberType returns the declared type of a member with pointers
+// removed, if it is a named non-struct type (such as "type Port int32")
+// whose markers apply to every field of that type. It returns nil otherwise.
+func namedMemberType(m types.Member) *types.Type {
+	t := m.Type
+	for t.Kind == types.Pointer {
+		t = t.Elem
+	}
+	if t.Kind != types.Alias {
+		return nil
+	}
+	return t
+}
+
+// markerArgs parses the "key=value,key=value" argument list of markers such
+// as "+kubebuilder:printcolumn". Values may be double-quoted (with backslash
+// escapes) or backtick-quoted in order to contain commas. Keys without a value
+// map to an empty string.
+func markerArgs(s string) map[string]string {
+	out := make(map[string]string)
+	for len(s) > 0 {
+		i := strings.IndexAny(s, "=,")
+		if i >= 0 && s[i] == '=' {
+			key := strings.TrimSpace(s[:i])
+			out[key], s = scanMarkerValue(s[i+1:])
+			continue
+		}
+
+		var key string
+		if i < 0 {
+			key, s = s, ""
+		} else {
+			key, s = s[:i], s[i+1:]
+		}
+		if key = strings.TrimSpace(key); key != "" {
+			out[key] = ""
+		}
+	}
+	return out
+}
+
+// scanMarkerValue reads a single argument value from the start of s and
+// returns it unquoted, along with the rest of s after the following comma.
+func scanMarkerValue(s string) (string, string) {
+	if len(s) > 0 && (s[0] == '"' || s[0] == '`') {
+		quote := s[0]
+		escaped := false
+		for i := 1; i < len(s); i++ {
+			switch {
+			case escaped:
+				escaped = false
+			case s[i] == '\\' && quote == '"':
+				escaped = true
+			case s[i] == quote:
+				value := unquoteMarkerValue(s[:i+1])
+				rest := s[i+1:]
+				if j := strings.IndexByte(rest, ','); j >= 0 {
+					return value, rest[j+1:]
+				}
+				return value, ""
+			}
+		}
+		// unterminated quote, take everything
+		return s[1:], ""
+	}
+	if i := strings.IndexByte(s, ','); i >= 0 {
+		return strings.TrimSpace(s[:i]), s[i+1:]
+	}
+	return strings.TrimSpace(s), ""
+}
+
+// markerList splits a ";"-separated marker argument such as
+// "shortName=foo;bar" into its items.
+func markerList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ";") {
+		if item = unquoteMarkerValue(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"k8s.io/gengo/types"
+)
+
+// flatMember is a field of a struct after the inline members are expanded.
+type flatMember struct {
+	Member types.Member
+	// From is the inline struct the field is declared in, nil for the
+	// fields of the struct itself.
+	From *types.Type
+
+	depth  int
+	tagged bool
+	hidden bool
+}
+
+// flattenMembers returns the visible fields of the struct t with its inline
+// members recursively replaced by their fields, in declaration order. Like
+// encoding/json, the fields of embedded structs without a JSON name are
+// inlined, whether or not they are tagged ",inline", and fields tagged "-"
+// or unexported are left out. Duplicate JSON names are resolved like
+// encoding/json does: the shallowest field wins, then the only one with a
+// JSON name tag, and if there is still more than one, none of them. Hidden
+// fields, and the fields of hidden inline members, take part in resolving
+// the duplicates before they are left out, as they are still serialized.
+func flattenMembers(t *types.Type, c GeneratorConfig) []flatMember {
+	var all []flatMember
+	visiting := make(map[*types.Type]bool)
+	var collect func(t, from *types.Type, depth int, hidden bool)
+	collect = func(t, from *types.Type, depth int, hidden bool) {
+		visiting[t] = true
+		defer delete(visiting, t)
+		for _, m := range t.Members {
+			tag := reflect.StructTag(m.Tags).Get("json")
+			if tag == "-" {
+				continue
+			}
+			name := strings.Split(tag, ",")[0]
+			if e := tryDereference(m.Type); (m.Embedded || fieldEmbedded(m)) && name == "" && e.Kind == types.Struct {
+				if !visiting[e] {
+					collect(e, e, depth+1, hidden || hiddenMember(m, c))
+				}
+				continue
+			}
+			if !isExportedName(m.Name) {
+				continue
+			}
+			all = append(all, flatMember{Member: m, From: from, depth: depth, tagged: name != "", hidden: hidden || hiddenMember(m, c)})
+		}
+	}
+	collect(t, nil, 0, false)
+
+	byName := make(map[string][]int)
+	for i, f := range all {
+		name := fieldName(f.Member)
+		byName[name] = append(byName[name], i)
+	}
+	var out []flatMember
+	for i, f := range all {
+		if !f.hidden && dominantMember(all, byName[fieldName(f.Member)]) == i {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// isExportedName reports whether the Go name of a field is exported, which
+// encoding/json requires to serialize it.
+func isExportedName(name string) bool {
+	return name != "" && unicode.IsUpper([]rune(name)[0])
+}
+
+// dominantMember returns the index of the field that is serialized among
+// the fields of all at indexes with the same JSON name, or -1 if none is.
+func dominantMember(all []flatMember, indexes []int) int {
+	depth := all[indexes[0]].depth
+	for _, i := range indexes {
+		if all[i].depth < depth {
+			depth = all[i].depth
+		}
+	}
+	var shallowest, tagged []int
+	for _, i := range indexes {
+		if all[i].depth == depth {
+			shallowest = append(shallowest, i)
+			if all[i].tagged {
+				tagged = append(tagged, i)
+			}
+		}
+	}
+	switch {
+	case len(shallowest) == 1:
+		return shallowest[0]
+	case len(tagged) == 1:
+		return tagged[0]
+	}
+	return -1
+}
+
+// typeMembers returns the members of t to document, with the inline
+// members flattened if the config says so.
+func typeMembers(t *types.Type, c GeneratorConfig) []types.Member {
+	if !c.FlattenInlineMembers {
+		return t.Members
+	}
+	var out []types.Member
+	for _, f := range flattenMembers(t, c) {
+		out = append(out, f.Member)
+	}
+	return out
+}
+
+// memberKey identifies a member among the flattened members of a type.
+type memberKey struct {
+	name string
+	typ  *types.Type
+	tags string
+}
+
+// memberOrigin returns the inline struct the flattened member m of t is
+// declared in, or nil if m is a field of t itself. The members of t are
+// only flattened once.
+func (r *renderer) memberOrigin(t *types.Type, m types.Member) *types.Type {
+	if !r.config.FlattenInlineMembers {
+		return nil
+	}
+	origins, ok := r.memberOrigins[t]
+	if !ok {
+		origins = make(map[memberKey]*types.Type)
+		for _, f := range flattenMembers(t, r.config) {
+			k := memberKey{f.Member.Name, f.Member.Type, f.Member.Tags}
+			if _, ok := origins[k]; !ok {
+				origins[k] = f.From
+			}
+		}
+		r.memberOrigins[t] = origins
+	}
+	return origins[memberKey{m.Name, m.Type, m.Tags}]
+}
+
+// expandConfig selects the fields whose type has its field table nested in
+// the description of the field, or in the rows following the field in the
+// Markdown and AsciiDoc tables.
+type expandConfig struct {
+	// Fields are the JSON names of the fields to expand.
+	Fields []string `json:"fields"`
+
+	// TypePatterns match the fully qualified names of the types whose
+	// fields are expanded.
+	TypePatterns []string `json:"typePatterns"`
+
+	// MaxDepth limits the nesting of the expanded tables, 0 for no limit.
+	MaxDepth int `json:"maxDepth"`
+
+	// typeRegexps are the compiled TypePatterns.
+	typeRegexps []*regexp.Regexp
+}
+
+// compile compiles the TypePatterns, once the config is loaded.
+func (e *expandConfig) compile() error {
+	e.typeRegexps = nil
+	for _, pattern := range e.TypePatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return errors.Wrapf(err, "invalid expand typePatterns entry %q", pattern)
+		}
+		e.typeRegexps = append(e.typeRegexps, re)
+	}
+	return nil
+}
+
+// defaultExpandConfig expands the spec of the Kinds, and the specs nested
+// in them.
+var defaultExpandConfig = &expandConfig{Fields: []string{"spec"}}
+
+// memberTable is a field table being rendered by the "members" template,
+// nested in the expanded fields of the tables of path.
+type memberTable struct {
+	*types.Type
+	path []*types.Type
+	// prefix is the JSON path of the expanded field, followed by a dot,
+	// e.g. "spec.".
+	prefix string
+}
+
+// memberExpansion is the expansion of a field of a memberTable.
+type memberExpansion struct {
+	// Table is the nested field table of the type of the field, nil if the
+	// type is already being expanded.
+	Table *memberTable
+	// Recursive is the type already being expanded, which the field should
+	// link back to instead.
+	Recursive *types.Type
+}
+
+// tableOf returns the memberTable of the data of the "members" template,
+// which is either a type or a nested memberTable.
+func tableOf(v interface{}) *memberTable {
+	switch v := v.(type) {
+	case *memberTable:
+		return v
+	case *types.Type:
+		return &memberTable{Type: v}
+	}
+	return nil
+}
+
+// expandMember returns how to expand the field m of the table, or nil if it
+// is not expanded.
+func expandMember(table *memberTable, m types.Member, c GeneratorConfig) *memberExpansion {
+	e := c.Expand
+	if e == nil {
+		e = defaultExpandConfig
+	}
+	t := tryDereference(m.Type)
+	if t.Kind != types.Struct || hideType(t, c) || !e.matches(m, t) {
+		return nil
+	}
+	path := append(append([]*types.Type{}, table.path...), table.Type)
+	for _, p := range path {
+		if p == t {
+			return &memberExpansion{Recursive: t}
+		}
+	}
+	if e.MaxDepth > 0 && len(path) > e.MaxDepth {
+		return nil
+	}
+	return &memberExpansion{Table: &memberTable{Type: t, path: path, prefix: memberPath(table, m) + "."}}
+}
+
+// memberPath returns the JSON path of the field m of the table, from the
+// type at the root of the table.
+func memberPath(table *memberTable, m types.Member) string {
+	return table.prefix + fieldName(m)
+}
+
+func (e *expandConfig) matches(m types.Member, t *types.Type) bool {
+	if containsString(e.Fields, fieldName(m)) {
+		return true
+	}
+	for _, re := range e.typeRegexps {
+		if re.MatchString(t.Name.String()) {
+			return true
+		}
+	}
+	return false
+}
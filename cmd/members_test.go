@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/types"
+)
+
+func TestFlattenMembers(t *testing.T) {
+	inner := &types.Type{
+		Name: types.Name{Package: "example.com/api/v1", Name: "Inner"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			testMember("Name", types.String, `json:"name"`),
+			testMember("Size", types.Int32, `json:"size"`),
+		},
+	}
+	embedded := func(tags string) types.Member {
+		m := testMember("Inner", inner, tags)
+		m.Embedded = true
+		return m
+	}
+	self := &types.Type{Name: types.Name{Package: "example.com/api/v1", Name: "Node"}, Kind: types.Struct}
+	selfEmbedded := testMember("Node", &types.Type{Kind: types.Pointer, Elem: self}, "")
+	selfEmbedded.Embedded = true
+	self.Members = []types.Member{selfEmbedded, testMember("Value", types.String, `json:"value"`)}
+
+	tests := []struct {
+		name   string
+		t      *types.Type
+		config GeneratorConfig
+		want   []string
+	}{
+		{
+			name: "inline tag",
+			t:    testStruct(embedded(`json:",inline"`), testMember("Color", types.String, `json:"color"`)),
+			want: []string{"name", "size", "color"},
+		},
+		{
+			name: "untagged embedded struct",
+			t:    testStruct(embedded(""), testMember("Color", types.String, `json:"color"`)),
+			want: []string{"name", "size", "color"},
+		},
+		{
+			name: "named embedded struct",
+			t:    testStruct(embedded(`json:"inner"`)),
+			want: []string{"inner"},
+		},
+		{
+			name: "ignored and unexported fields",
+			t: testStruct(
+				testMember("Color", types.String, `json:"-"`),
+				testMember("shape", types.String, ""),
+				testMember("Size", types.Int32, `json:"size"`),
+			),
+			want: []string{"size"},
+		},
+		{
+			name: "shallower field wins",
+			t:    testStruct(embedded(""), testMember("Size", types.String, `json:"size"`)),
+			want: []string{"name", "size"},
+		},
+		{
+			name:   "hidden field shadows a nested field",
+			t:      testStruct(embedded(""), testMember("Size", types.String, `json:"size"`)),
+			config: GeneratorConfig{HiddenMemberFields: []string{"Size"}},
+			want:   []string{"name"},
+		},
+		{
+			name:   "fields of hidden inline member",
+			t:      testStruct(embedded(`json:",inline"`), testMember("Color", types.String, `json:"color"`)),
+			config: GeneratorConfig{HiddenMemberFields: []string{"Inner"}},
+			want:   []string{"color"},
+		},
+		{
+			name: "ambiguous fields",
+			t: testStruct(
+				embedded(""),
+				func() types.Member {
+					m := testMember("Other", &types.Type{Name: types.Name{Name: "Other"}, Kind: types.Struct, Members: []types.Member{testMember("Name", types.String, `json:"name"`)}}, "")
+					m.Embedded = true
+					return m
+				}(),
+			),
+			want: []string{"size"},
+		},
+		{
+			name: "self-recursive embedded struct",
+			t:    self,
+			want: []string{"value"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range flattenMembers(tt.t, tt.config) {
+				got = append(got, fieldName(f.Member))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("flattenMembers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlattenMembersOrigin(t *testing.T) {
+	inner := &types.Type{
+		Name:    types.Name{Package: "example.com/api/v1", Name: "Inner"},
+		Kind:    types.Struct,
+		Members: []types.Member{testMember("Name", types.String, `json:"name"`)},
+	}
+	m := testMember("Inner", inner, "")
+	m.Embedded = true
+	got := flattenMembers(testStruct(m, testMember("Size", types.Int32, `json:"size"`)), GeneratorConfig{})
+	if len(got) != 2 || got[0].From != inner || got[1].From != nil {
+		t.Errorf("flattenMembers() = %+v, want name from Inner and size from the struct itself", got)
+	}
+}
+
+// testRecursiveTree returns the Kind Widget whose spec has a root of the
+// self-recursive type Node.
+func testRecursiveTree() (kind, spec, node *types.Type) {
+	node = &types.Type{Name: types.Name{Package: "example.com/api/v1", Name: "Node"}, Kind: types.Struct}
+	node.Members = []types.Member{
+		testMember("Next", &types.Type{Kind: types.Pointer, Elem: node}, `json:"next,omitempty"`),
+		testMember("Value", types.String, `json:"value"`, "Value is the value."),
+	}
+	spec = testStruct(
+		testMember("Size", types.Int32, `json:"size"`, "Size is the size."),
+		testMember("Root", node, `json:"root"`, "Root is the first node."),
+	)
+	kind = testKind("Widget")
+	kind.Members = []types.Member{testMember("Spec", spec, `json:"spec"`, "Spec is the spec.")}
+	return kind, spec, node
+}
+
+func TestExpandMember(t *testing.T) {
+	kind, spec, node := testRecursiveTree()
+	specMember, rootMember, nextMember := kind.Members[0], spec.Members[1], node.Members[0]
+	specTable := &memberTable{Type: spec, path: []*types.Type{kind}, prefix: "spec."}
+	nodeTable := &memberTable{Type: node, path: []*types.Type{kind, spec}, prefix: "spec.root."}
+
+	tests := []struct {
+		name   string
+		table  *memberTable
+		member types.Member
+		expand *expandConfig
+		// want is the JSON path prefix of the nested table, "recursive" if
+		// the field links back to its type and "" if it is not expanded
+		want string
+	}{
+		{"spec by default", &memberTable{Type: kind}, specMember, nil, "spec."},
+		{"unselected field", specTable, rootMember, nil, ""},
+		{"selected field", specTable, rootMember, &expandConfig{Fields: []string{"spec", "root"}}, "spec.root."},
+		{"selected type", specTable, rootMember, &expandConfig{Fields: []string{"spec"}, TypePatterns: []string{`\.Node$`}}, "spec.root."},
+		{"non-struct field", &memberTable{Type: spec}, spec.Members[0], &expandConfig{Fields: []string{"size"}}, ""},
+		{"self-recursive type", nodeTable, nextMember, &expandConfig{Fields: []string{"next"}}, "recursive"},
+		{"self-recursive type at the root", &memberTable{Type: node}, nextMember, &expandConfig{Fields: []string{"next"}}, "recursive"},
+		{"within the depth", specTable, rootMember, &expandConfig{Fields: []string{"root"}, MaxDepth: 2}, "spec.root."},
+		{"beyond the depth", specTable, rootMember, &expandConfig{Fields: []string{"root"}, MaxDepth: 1}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expand != nil {
+				if err := tt.expand.compile(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			e := expandMember(tt.table, tt.member, GeneratorConfig{Expand: tt.expand})
+			var got string
+			switch {
+			case e == nil:
+			case e.Recursive != nil:
+				if e.Recursive != node {
+					t.Errorf("recursive type = %s, want Node", e.Recursive.Name)
+				}
+				got = "recursive"
+			default:
+				got = e.Table.prefix
+				if e.Table.Type != tryDereference(tt.member.Type) {
+					t.Errorf("table of %s, want the type of the field", e.Table.Type.Name)
+				}
+			}
+			if got != tt.want {
+				t.Errorf("expandMember() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandedMemberRows(t *testing.T) {
+	kind, spec, node := testRecursiveTree()
+	pkg := &apiPackage{
+		apiGroup:   "widgets.example.com",
+		apiVersion: "v1",
+		GoPackages: []*types.Package{{Path: "example.com/api/v1", Name: "v1"}},
+		Types:      []*types.Type{kind, spec, node},
+	}
+	config := GeneratorConfig{Expand: &expandConfig{Fields: []string{"spec", "root", "next"}}}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{formatMarkdown, []string{
+			"| `spec` _[WidgetSpec](#widgets-example-com-v1-widgetspec)_ | Spec is the spec. |\n" +
+				"| `spec.size` _int32_ | Size is the size. |\n" +
+				"| `spec.root` _[Node](#widgets-example-com-v1-node)_ | Root is the first node. |\n" +
+				"| `spec.root.next` _[Node](#widgets-example-com-v1-node)_ | _(Recursive, see [Node](#widgets-example-com-v1-node).)_ |\n" +
+				"| `spec.root.value` _string_ | Value is the value. |\n",
+		}},
+		{formatAsciiDoc, []string{
+			"| `spec.root` _xref:widgets-example-com-v1-node[$$Node$$]_\n" +
+				"| Root is the first node.\n" +
+				"| `spec.root.next` _xref:widgets-example-com-v1-node[$$Node$$]_\n",
+			"_(Recursive, see xref:widgets-example-com-v1-node[$$Node$$].)_\n" +
+				"| `spec.root.value` _$$string$$_\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if err := config.Expand.compile(); err != nil {
+				t.Fatal(err)
+			}
+			r := newRenderer([]*apiPackage{pkg}, config, tt.format)
+			var b bytes.Buffer
+			if err := r.render(&b, &page{Kind: pageSingle, Packages: []*apiPackage{pkg}}, &provenance{}); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("output does not contain\n%s\ngot\n%s", want, b.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/gengo/types"
+)
+
+// docModelVersion identifies the layout of the -format json document. It
+// changes whenever a field is removed or changes meaning; fields may be added
+// without changing it.
+const docModelVersion = "v1"
+
+// docModel is the documentation of the API packages as rendered by
+// -format json, for tools that cannot consume the gengo types.
+type docModel struct {
+	ModelVersion string      `json:"modelVersion"`
+	Provenance   *provenance `json:"provenance"`
+	Groups       []*docGroup `json:"groups"`
+}
+
+type docGroup struct {
+	Name string `json:"name"`
+	// Versions are ordered from the oldest and least stable to the newest.
+	Versions []*docVersion `json:"versions"`
+	Kinds    []*docKind    `json:"kinds"`
+}
+
+// docKind is a Kind of a group, across the versions that define it.
+type docKind struct {
+	Kind     string            `json:"kind"`
+	Versions []*docKindVersion `json:"versions"`
+}
+
+type docKindVersion struct {
+	Version            string `json:"version"`
+	TypeID             string `json:"typeID"`
+	Storage            bool   `json:"storage"`
+	Served             bool   `json:"served"`
+	Deprecated         bool   `json:"deprecated"`
+	DeprecationWarning string `json:"deprecationWarning,omitempty"`
+}
+
+// docVersion is an API group version, i.e. an apiPackage.
+type docVersion struct {
+	Name       string   `json:"name"`
+	APIVersion string   `json:"apiVersion"`
+	GoPackages []string `json:"goPackages"`
+	// Description is the package doc comment, in Markdown.
+	Description string     `json:"description,omitempty"`
+	Anchor      string     `json:"anchor"`
+	Types       []*docType `json:"types"`
+}
+
+type docType struct {
+	// ID is the fully qualified Go name of the type, as typeIdentifier
+	// returns it.
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	IsKind bool   `json:"isKind"`
+	Anchor string `json:"anchor"`
+	// Description is the type doc comment without markers, in Markdown.
+	Description string `json:"description,omitempty"`
+	// Underlying is the type an alias is declared as.
+	Underlying *docTypeRef    `json:"underlying,omitempty"`
+	Resource   *resourceInfo  `json:"resource,omitempty"`
+	Enum       []docEnumValue `json:"enum,omitempty"`
+	Fields     []*docField    `json:"fields,omitempty"`
+	// AppearsIn lists the visible types that have fields of this type.
+	AppearsIn []*docTypeRef `json:"appearsIn,omitempty"`
+	// Source is where the type is declared, if it is in a git repository.
+	Source *sourcePos `json:"source,omitempty"`
+}
+
+// docTypeRef refers to a type from a field or another type.
+type docTypeRef struct {
+	ID      string `json:"id"`
+	Display string `json:"display"`
+	// Link is an "#anchor" for types of the documented packages, or the URL
+	// of the documentation of external types if it is known.
+	Link string `json:"link,omitempty"`
+}
+
+type docField struct {
+	// Name is the JSON name of the field.
+	Name        string      `json:"name"`
+	GoName      string      `json:"goName"`
+	Type        *docTypeRef `json:"type"`
+	Embedded    bool        `json:"embedded,omitempty"`
+	Optional    bool        `json:"optional"`
+	Description string      `json:"description,omitempty"`
+	// Default is the decoded default value, if any.
+	Default    interface{}      `json:"default,omitempty"`
+	Validation []validationRule `json:"validation,omitempty"`
+	Enum       []docEnumValue   `json:"enum,omitempty"`
+	// Source is where the field is declared, if it is in a git repository.
+	Source *sourcePos `json:"source,omitempty"`
+}
+
+type docEnumValue struct {
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+// writeDocModel renders the document model of r as indented JSON.
+func (r *renderer) writeDocModel(w io.Writer, prov *provenance) error {
+	m, err := r.docModel(prov)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return errors.Wrap(enc.Encode(m), "failed to encode the document model")
+}
+
+func (r *renderer) docModel(prov *provenance) (*docModel, error) {
+	out := &docModel{ModelVersion: docModelVersion, Provenance: prov, Groups: []*docGroup{}}
+	for _, g := range r.groups {
+		group := &docGroup{Name: g.Group, Versions: []*docVersion{}, Kinds: []*docKind{}}
+		for _, version := range g.Versions {
+			for _, pkg := range r.pkgs {
+				if pkg.apiGroup != g.Group || pkg.apiVersion != version {
+					continue
+				}
+				v, err := r.docVersion(pkg)
+				if err != nil {
+					return nil, err
+				}
+				group.Versions = append(group.Versions, v)
+			}
+		}
+		for _, k := range g.Kinds {
+			kind := &docKind{Kind: k.Kind}
+			for _, kv := range k.Versions {
+				if kv == nil {
+					continue
+				}
+				kind.Versions = append(kind.Versions, &docKindVersion{
+					Version:            kv.Version,
+					TypeID:             typeIdentifier(kv.Type),
+					Storage:            kv.Storage,
+					Served:             kv.Served,
+					Deprecated:         kv.Deprecated,
+					DeprecationWarning: kv.DeprecationWarning,
+				})
+			}
+			group.Kinds = append(group.Kinds, kind)
+		}
+		out.Groups = append(out.Groups, group)
+	}
+	return out, nil
+}
+
+func (r *renderer) docVersion(pkg *apiPackage) (*docVersion, error) {
+	v := &docVersion{
+		Name:       pkg.apiVersion,
+		APIVersion: pkg.groupVersion(),
+		GoPackages: []string{},
+		Anchor:     strings.Replace(pkg.identifier(), " ", "", -1),
+		Types:      []*docType{},
+	}
+	for _, p := range pkg.GoPackages {
+		v.GoPackages = append(v.GoPackages, p.Path)
+	}
+	if len(pkg.GoPackages) > 0 {
+		v.Description = commentText(pkg.GoPackages[0].DocComments)
+	}
+	for _, t := range visibleTypes(sortTypes(pkg.Types), r.config) {
+		dt, err := r.docType(t, pkg)
+		if err != nil {
+			return nil, err
+		}
+		v.Types = append(v.Types, dt)
+	}
+	return v, nil
+}
+
+func (r *renderer) docType(t *types.Type, pkg *apiPackage) (*docType, error) {
+	dt := &docType{
+		ID:          typeIdentifier(t),
+		Name:        t.Name.Name,
+		IsKind:      isExportedType(t),
+		Anchor:      anchorIDForLocalType(t, r.typePkgMap),
+		Description: commentText(t.CommentLines),
+		Resource:    resourceForType(t),
+		Enum:        docEnum(typeEnum(t, pkg)),
+		Source:      r.typeSource(t),
+	}
+	if t.Kind == types.Alias {
+		ref, err := r.docTypeRef(t.Underlying)
+		if err != nil {
+			return nil, err
+		}
+		dt.Underlying = ref
+	}
+	for _, m := range t.Members {
+		if hiddenMember(m, r.config) {
+			continue
+		}
+		f, err := r.docField(t, m)
+		if err != nil {
+			return nil, err
+		}
+		dt.Fields = append(dt.Fields, f)
+	}
+	for _, ref := range typeReferences(t, r.config, r.references) {
+		dr, err := r.docTypeRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		dt.AppearsIn = append(dt.AppearsIn, dr)
+	}
+	return dt, nil
+}
+
+func (r *renderer) docField(t *types.Type, m types.Member) (*docField, error) {
+	ref, err := r.docTypeRef(m.Type)
+	if err != nil {
+		return nil, err
+	}
+	f := &docField{
+		Name:        fieldName(m),
+		GoName:      m.Name,
+		Type:        ref,
+		Embedded:    fieldEmbedded(m),
+		Optional:    isOptionalMember(m),
+		Description: commentText(m.CommentLines),
+		Enum:        docEnum(memberEnum(m, r.typePkgMap)),
+		Source:      r.memberSource(t, m),
+	}
+	if d, _ := memberDefault(m); d != nil { // reported by warnInvalidMarkers
+		f.Default = d.Value
+	}
+	if v := memberValidation(m); v != nil {
+		f.Validation = v.Rules
+	}
+	return f, nil
+}
+
+func (r *renderer) docTypeRef(t *types.Type) (*docTypeRef, error) {
+	link, err := linkForType(t, r.config, r.typePkgMap, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting link for type=%s", t.Name)
+	}
+	return &docTypeRef{
+		ID:      typeIdentifier(t),
+		Display: typeDisplayName(t, r.config, r.typePkgMap),
+		Link:    link,
+	}, nil
+}
+
+func docEnum(e *enumModel) []docEnumValue {
+	if e == nil {
+		return nil
+	}
+	var out []docEnumValue
+	for _, v := range e.Allowed() {
+		dv := docEnumValue{Value: v.Value}
+		if v.Constant != nil {
+			dv.Description = commentText(v.Constant.CommentLines)
+		}
+		out = append(out, dv)
+	}
+	return out
+}
+
+// commentText returns a doc comment without its markers, as Markdown.
+func commentText(lines []string) string {
+	return strings.TrimSpace(strings.Join(filterCommentTags(lines), "\n"))
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"k8s.io/gengo/types"
+)
+
+// testDocModel is the document model of the package of TestWriteDocModel.
+const testDocModel = `{
+  "modelVersion": "v1",
+  "provenance": {
+    "commit": "abc1234",
+    "timestamp": "2021-02-03T04:05:06Z",
+    "configHash": "c0ffee"
+  },
+  "groups": [
+    {
+      "name": "widgets.example.com",
+      "versions": [
+        {
+          "name": "v1",
+          "apiVersion": "widgets.example.com/v1",
+          "goPackages": [],
+          "anchor": "widgets.example.com/v1",
+          "types": [
+            {
+              "id": "example.com/api/v1.Widget",
+              "name": "Widget",
+              "isKind": true,
+              "anchor": "widgets-example-com-v1-widget",
+              "description": "Widget is a widget.",
+              "resource": {
+                "scope": "Cluster",
+                "plural": "widgets",
+                "singular": "widget",
+                "statusSubresource": false
+              },
+              "fields": [
+                {
+                  "name": "spec",
+                  "goName": "Spec",
+                  "type": {
+                    "id": "example.com/api/v1.WidgetSpec",
+                    "display": "WidgetSpec",
+                    "link": "#widgets-example-com-v1-widgetspec"
+                  },
+                  "optional": false
+                }
+              ]
+            },
+            {
+              "id": "example.com/api/v1.Mode",
+              "name": "Mode",
+              "isKind": false,
+              "anchor": "widgets-example-com-v1-mode",
+              "description": "Mode is how fast a widget goes.",
+              "underlying": {
+                "id": "string",
+                "display": "string"
+              },
+              "enum": [
+                {
+                  "value": "Fast"
+                },
+                {
+                  "value": "Slow"
+                }
+              ],
+              "appearsIn": [
+                {
+                  "id": "example.com/api/v1.WidgetSpec",
+                  "display": "WidgetSpec",
+                  "link": "#widgets-example-com-v1-widgetspec"
+                }
+              ]
+            },
+            {
+              "id": "example.com/api/v1.WidgetSpec",
+              "name": "WidgetSpec",
+              "isKind": false,
+              "anchor": "widgets-example-com-v1-widgetspec",
+              "fields": [
+                {
+                  "name": "replicas",
+                  "goName": "Replicas",
+                  "type": {
+                    "id": "int32",
+                    "display": "int32"
+                  },
+                  "optional": false,
+                  "description": "Replicas is the number of widgets.",
+                  "validation": [
+                    {
+                      "marker": "Minimum",
+                      "value": "1"
+                    }
+                  ]
+                },
+                {
+                  "name": "mode",
+                  "goName": "Mode",
+                  "type": {
+                    "id": "example.com/api/v1.Mode",
+                    "display": "Mode",
+                    "link": "#widgets-example-com-v1-mode"
+                  },
+                  "optional": true,
+                  "default": "Fast",
+                  "enum": [
+                    {
+                      "value": "Fast"
+                    },
+                    {
+                      "value": "Slow"
+                    }
+                  ]
+                }
+              ],
+              "appearsIn": [
+                {
+                  "id": "example.com/api/v1.Widget",
+                  "display": "Widget",
+                  "link": "#widgets-example-com-v1-widget"
+                }
+              ]
+            }
+          ]
+        }
+      ],
+      "kinds": [
+        {
+          "kind": "Widget",
+          "versions": [
+            {
+              "version": "v1",
+              "typeID": "example.com/api/v1.Widget",
+              "storage": true,
+              "served": true,
+              "deprecated": false
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`
+
+func TestWriteDocModel(t *testing.T) {
+	mode := &types.Type{
+		Name:         types.Name{Package: "example.com/api/v1", Name: "Mode"},
+		Kind:         types.Alias,
+		Underlying:   types.String,
+		CommentLines: []string{"Mode is how fast a widget goes.", "+kubebuilder:validation:Enum=Fast;Slow"},
+	}
+	spec := testStruct(
+		testMember("Replicas", types.Int32, `json:"replicas"`, "Replicas is the number of widgets.", "+kubebuilder:validation:Minimum=1"),
+		testMember("Mode", mode, `json:"mode,omitempty"`, "+optional", "+kubebuilder:default=Fast"),
+	)
+	widget := testKind("Widget", "+kubebuilder:resource:scope=Cluster", "+kubebuilder:storageversion")
+	widget.CommentLines = []string{"Widget is a widget."}
+	widget.Members = []types.Member{testMember("Spec", spec, `json:"spec"`)}
+	pkg := &apiPackage{apiGroup: "widgets.example.com", apiVersion: "v1", Types: []*types.Type{widget, spec, mode}}
+
+	r := newRenderer([]*apiPackage{pkg}, GeneratorConfig{}, formatJSON)
+	var b bytes.Buffer
+	if err := r.writeDocModel(&b, &provenance{Commit: "abc1234", Timestamp: testCommitDate, ConfigHash: "c0ffee"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != testDocModel {
+		t.Errorf("writeDocModel() =\n%s\nwant\n%s", got, testDocModel)
+	}
+}
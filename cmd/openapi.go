@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/gengo/types"
+
+	texttemplate "text/template"
+)
+
+const openAPIVersion = "3.0.3"
+
+// openAPIConfig configures the -format openapi output.
+type openAPIConfig struct {
+	// Title and Version are the info of the document. Version defaults to
+	// the git commit.
+	Title   string `json:"title"`
+	Version string `json:"version"`
+
+	// KubernetesRefTemplate is a template of the $ref to the upstream
+	// definition of the types of k8s.io packages, given their definition
+	// .Name (e.g. "io.k8s.api.core.v1.Container"), .PackagePath and
+	// .TypeIdentifier. If it is empty, these types are opaque objects.
+	KubernetesRefTemplate string `json:"kubernetesRefTemplate"`
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                 `json:"openapi"`
+	Info       openAPIInfo            `json:"info"`
+	Paths      map[string]interface{} `json:"paths"`
+	Components openAPIComponents      `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*jsonSchema `json:"schemas"`
+}
+
+// writeOpenAPI renders an OpenAPI v3 document with a component schema for
+// every visible type, keyed by definitionName: the typeIdentifier of the
+// type, with "/" and the other characters component names cannot have
+// replaced by "_".
+func (r *renderer) writeOpenAPI(w io.Writer, prov *provenance) (err error) {
+	var c openAPIConfig
+	if r.config.OpenAPI != nil {
+		c = *r.config.OpenAPI
+	}
+	doc := &openAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info:    openAPIInfo{Title: c.Title, Version: c.Version},
+		Paths:   map[string]interface{}{},
+	}
+	if doc.Info.Title == "" {
+		doc.Info.Title = "API Reference"
+	}
+	if doc.Info.Version == "" {
+		doc.Info.Version = prov.Tag
+	}
+	if doc.Info.Version == "" {
+		doc.Info.Version = prov.Commit
+	}
+	if doc.Info.Version == "" {
+		doc.Info.Version = "unversioned"
+	}
+
+	b := newSchemaBuilder(r, "#/components/schemas/")
+	b.openAPI = true
+	if c.KubernetesRefTemplate != "" {
+		var tpl *texttemplate.Template
+		tpl, err = texttemplate.New("").Parse(c.KubernetesRefTemplate)
+		if err != nil {
+			return errors.Wrap(err, "kubernetesRefTemplate failed to parse")
+		}
+		var refErr error
+		b.externalRef = func(t *types.Type) *jsonSchema {
+			if !strings.HasPrefix(t.Name.Package, "k8s.io/") {
+				return nil
+			}
+			var ref bytes.Buffer
+			if err := tpl.Execute(&ref, map[string]interface{}{
+				"Name":           openAPIDefinitionName(t),
+				"PackagePath":    t.Name.Package,
+				"TypeIdentifier": typeIdentifier(t),
+			}); err != nil && refErr == nil {
+				refErr = errors.Wrap(err, "kubernetesRefTemplate execution error")
+			}
+			return &jsonSchema{Ref: ref.String()}
+		}
+		defer func() {
+			if err == nil {
+				err = refErr
+			}
+		}()
+	}
+
+	for _, pkg := range r.pkgs {
+		for _, t := range visibleTypes(sortTypes(pkg.Types), r.config) {
+			name := definitionName(t)
+			switch t.Kind {
+			case types.Struct:
+				b.ref(t)
+				if isExportedType(t) {
+					s := b.definitions[name]
+					addTypeMeta(s, t, pkg)
+					s.XGroupVersionKind = []*groupVersionKind{{Group: pkg.apiGroup, Version: pkg.apiVersion, Kind: t.Name.Name}}
+				}
+			case types.Alias:
+				s := b.typeSchema(t.Underlying)
+				s.Description = commentText(t.CommentLines)
+				if d, _ := typeDefault(t); d != nil {
+					s.Default = d.Value
+				}
+				if e := typeEnum(t, pkg); e != nil {
+					for _, v := range e.Allowed() {
+						s.Enum = append(s.Enum, enumSchemaValue(s.Type, v.Value))
+					}
+				}
+				if v := typeValidation(t); v != nil {
+					b.applyValidation(s, v)
+				}
+				b.definitions[name] = s
+			}
+		}
+	}
+	doc.Components.Schemas = b.definitions
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return errors.Wrap(enc.Encode(doc), "failed to encode the OpenAPI document")
+}
+
+// openAPIDefinitionName returns the name Kubernetes gives to the OpenAPI
+// definition of t, e.g. "io.k8s.api.core.v1.Container" for
+// k8s.io/api/core/v1.Container.
+func openAPIDefinitionName(t *types.Type) string {
+	segments := strings.Split(t.Name.Package, "/")
+	domain := strings.Split(segments[0], ".")
+	for i, j := 0, len(domain)-1; i < j; i, j = i+1, j-1 {
+		domain[i], domain[j] = domain[j], domain[i]
+	}
+	segments[0] = strings.Join(domain, ".")
+	return strings.Join(segments, ".") + "." + t.Name.Name
+}
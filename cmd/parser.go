@@ -1,12 +1,14 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"os"
 	"reflect"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -14,6 +16,7 @@ import (
 	"k8s.io/gengo/parser"
 	"k8s.io/gengo/types"
 	"k8s.io/klog"
+	"sigs.k8s.io/yaml"
 )
 
 var (
@@ -48,6 +51,36 @@ type GeneratorConfig struct {
 
 	// GitCommitDisabled causes the git commit information to be excluded from the output.
 	GitCommitDisabled bool `json:"gitCommitDisabled"`
+
+	// SiteGenerator adds the front matter and navigation files of a static
+	// site generator to the -out-dir Markdown output.
+	SiteGenerator *siteGeneratorConfig `json:"siteGenerator"`
+
+	// FlattenInlineMembers lists the fields of inline members in the field
+	// table of the type that embeds them, rather than the inline member
+	// itself.
+	FlattenInlineMembers bool `json:"flattenInlineMembers"`
+
+	// Expand selects the fields whose type has its field table nested in
+	// the documentation of the field, by default the fields named "spec".
+	Expand *expandConfig `json:"expand"`
+
+	// ExamplesDir is a directory of example manifests, shown with the Kind
+	// matching their apiVersion and kind.
+	ExamplesDir string `json:"examplesDir"`
+
+	// OpenAPI configures the -format openapi document.
+	OpenAPI *openAPIConfig `json:"openAPI"`
+
+	// SourceLinkTemplate is a template of the link to where a type or field
+	// is declared, given the git .Commit of -api-dir, the .Path of the file
+	// relative to the root of the repository and the .Line.
+	SourceLinkTemplate string `json:"sourceLinkTemplate"`
+
+	// MarkdownHeadingIDs keeps the {#id} attributes of the headings of the
+	// markdown format, for MkDocs, instead of linking to the anchors GitHub
+	// generates from the heading text. Site generators always keep them.
+	MarkdownHeadingIDs bool `json:"markdownHeadingIDs"`
 }
 
 type externalPackage struct {
@@ -61,10 +94,22 @@ type apiPackage struct {
 	GoPackages []*types.Package
 	Types      []*types.Type // because multiple 'types.Package's can add types to an apiVersion
 	Constants  []*types.Type
+	// sources are where the types and their members are declared, only
+	// read for the source links, see renderer.loadSourceLinks.
+	sources map[string]*sourcePos
 }
 
 func (v *apiPackage) identifier() string { return fmt.Sprintf("%s/%s", v.apiGroup, v.apiVersion) }
 
+// groupVersion returns the apiVersion of the objects of v in manifests,
+// which is only the version for the core group.
+func (v *apiPackage) groupVersion() string {
+	if v.apiGroup == "" {
+		return v.apiVersion
+	}
+	return v.apiGroup + "/" + v.apiVersion
+}
+
 // groupName extracts the "//+groupName" meta-comment from the specified
 // package's comments, or returns empty string if it cannot be found.
 func groupName(pkg *types.Package) string {
@@ -79,7 +124,7 @@ func groupName(pkg *types.Package) string {
 func ParseAPIPackages(dir string) ([]*types.Package, error) {
 	b := parser.New()
 	// the following will silently fail (turn on -v=4 to see logs)
-	if err := b.AddDirRecursive(*flAPIDir); err != nil {
+	if err := b.AddDirRecursive(dir); err != nil {
 		return nil, err
 	}
 	scan, err := b.FindTypes()
@@ -162,7 +207,7 @@ func combineAPIPackages(pkgs []*types.Package) ([]*apiPackage, error) {
 			pkgIds = append(pkgIds, id)
 		} else {
 			v.Types = append(v.Types, flattenTypes(pkg.Types)...)
-			v.Constants = append(v.Types, flattenTypes(pkg.Constants)...)
+			v.Constants = append(v.Constants, flattenTypes(pkg.Constants)...)
 			v.GoPackages = append(v.GoPackages, pkg)
 		}
 	}
@@ -208,6 +253,20 @@ func isExportedType(t *types.Type) bool {
 	return false
 }
 
+// isListType reports whether t is the list type of a Kind, such as
+// WidgetList, which is a root object but not a resource of its own.
+func isListType(t *types.Type) bool {
+	if !strings.HasSuffix(t.Name.Name, "List") {
+		return false
+	}
+	for _, m := range t.Members {
+		if m.Name == "Items" {
+			return true
+		}
+	}
+	return false
+}
+
 func fieldName(m types.Member) string {
 	v := reflect.StructTag(m.Tags).Get("json")
 	v = strings.TrimSuffix(v, ",omitempty")
@@ -292,7 +351,17 @@ func sortTypes(typs []*types.Type) []*types.Type {
 
 func filterCommentTags(comments []string) []string {
 	var out []string
-	for _, v := range comments {
+	for i := 0; i < len(comments); i++ {
+		v := comments[i]
+		if hasMarker(comments[i:i+1], exampleMarker) {
+			// the example is rendered on its own, and so is the empty line
+			// ending it when one precedes it
+			i = exampleBlockEnd(comments, i) - 1
+			if n := len(out); n > 0 && strings.TrimSpace(out[n-1]) == "" && i+1 < len(comments) && strings.TrimSpace(comments[i+1]) == "" {
+				i++
+			}
+			continue
+		}
 		if !strings.HasPrefix(strings.TrimSpace(v), "+") {
 			out = append(out, v)
 		}
@@ -306,6 +375,167 @@ func isOptionalMember(m types.Member) bool {
 	return ok
 }
 
+// fieldDefault is the default value of a field, as declared with a
+// "+kubebuilder:default" or "+default" marker.
+type fieldDefault struct {
+	// Literal is the marker argument as written in the source.
+	Literal string
+	// Value is the decoded JSON/YAML literal.
+	Value interface{}
+}
+
+// String renders the default as compact JSON, which is also valid in YAML
+// manifests.
+func (d *fieldDefault) String() string {
+	b, err := json.Marshal(d.Value)
+	if err != nil {
+		return d.Literal
+	}
+	return string(b)
+}
+
+// memberDefault returns the default value of m, falling back to the default
+// declared on the member's named type. It returns nil if there is none, and
+// an error if the literal cannot be decoded.
+func memberDefault(m types.Member) (*fieldDefault, error) {
+	literal, ok := defaultMarkerValue(m.CommentLines)
+	if !ok {
+		if t := namedMemberType(m); t != nil {
+			literal, ok = defaultMarkerValue(typeCommentLines(t))
+		}
+	}
+	if !ok {
+		return nil, nil
+	}
+	return decodeDefault(literal)
+}
+
+// typeDefault returns the default value of the named type t, if any.
+func typeDefault(t *types.Type) (*fieldDefault, error) {
+	literal, ok := defaultMarkerValue(typeCommentLines(t))
+	if !ok {
+		return nil, nil
+	}
+	return decodeDefault(literal)
+}
+
+func decodeDefault(literal string) (*fieldDefault, error) {
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(literal), &v); err != nil {
+		return nil, errors.Wrapf(err, "cannot decode default value %q", literal)
+	}
+	return &fieldDefault{Literal: literal, Value: v}, nil
+}
+
+func defaultMarkerValue(lines []string) (string, bool) {
+	if v, ok := lastMarkerValue(lines, "kubebuilder:default"); ok {
+		return v, true
+	}
+	return lastMarkerValue(lines, "default")
+}
+
+// warnInvalidMarkers logs every member whose default value cannot be decoded,
+// and every enum type whose Enum marker disagrees with its constants.
+// Templates render such fields without a default.
+func warnInvalidMarkers(pkgs []*apiPackage) {
+	for _, pkg := range pkgs {
+		for _, t := range pkg.Types {
+			if e := typeEnum(t, pkg); e != nil && e.Mismatch {
+				klog.Warningf("type %s: +%s values %v do not match its constants %v",
+					t.Name, enumMarker, e.markerValues(), e.constantValues())
+			}
+			for _, m := range t.Members {
+				if _, err := memberDefault(m); err != nil {
+					klog.Warningf("type %s field %s: %v", t.Name, fieldName(m), err)
+				}
+			}
+		}
+	}
+}
+
+const validationMarkerPrefix = "kubebuilder:validation:"
+
+// validationMarkers lists the supported "+kubebuilder:validation:<Marker>"
+// markers in the order they are displayed.
+var validationMarkers = []string{
+	"Type",
+	"Format",
+	"Minimum",
+	"ExclusiveMinimum",
+	"Maximum",
+	"ExclusiveMaximum",
+	"MultipleOf",
+	"MinLength",
+	"MaxLength",
+	"Pattern",
+	"MinItems",
+	"MaxItems",
+	"UniqueItems",
+	"MinProperties",
+	"MaxProperties",
+}
+
+// validationRule is a single validation constraint on a field.
+type validationRule struct {
+	// Marker is the marker name without the "+kubebuilder:validation:"
+	// prefix, e.g. "Minimum".
+	Marker string `json:"marker"`
+	// Value is the marker argument with surrounding quotes removed.
+	Value string `json:"value"`
+}
+
+// fieldValidation is the set of validation constraints that apply to a field,
+// either declared on the field itself or on its named type.
+type fieldValidation struct {
+	Rules []validationRule
+}
+
+// Get returns the value of the given marker, if it is set.
+func (v *fieldValidation) Get(marker string) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	for _, r := range v.Rules {
+		if r.Marker == marker {
+			return r.Value, true
+		}
+	}
+	return "", false
+}
+
+// memberValidation collects the validation markers of m. Markers on the
+// member's named type (e.g. "type Port int32") apply too, unless the member
+// overrides them. Returns nil if there are no constraints.
+func memberValidation(m types.Member) *fieldValidation {
+	var lines []string
+	if t := namedMemberType(m); t != nil {
+		lines = append(lines, typeCommentLines(t)...)
+	}
+	lines = append(lines, m.CommentLines...)
+	return markerValidation(lines)
+}
+
+// typeValidation collects the validation markers of the named type t.
+func typeValidation(t *types.Type) *fieldValidation {
+	return markerValidation(typeCommentLines(t))
+}
+
+func markerValidation(lines []string) *fieldValidation {
+	var v fieldValidation
+	for _, marker := range validationMarkers {
+		if value, ok := lastMarkerValue(lines, validationMarkerPrefix+marker); ok {
+			if value == "" {
+				value = "true" // boolean markers such as UniqueItems
+			}
+			v.Rules = append(v.Rules, validationRule{Marker: marker, Value: unquoteMarkerValue(value)})
+		}
+	}
+	if len(v.Rules) == 0 {
+		return nil
+	}
+	return &v
+}
+
 func apiVersionForPackage(pkg *types.Package) (string, string, error) {
 	group := groupName(pkg)
 	version := pkg.Name // assumes basename (i.e. "v1" in "core/v1") is apiVersion
@@ -340,3 +570,146 @@ func constantsOfType(t *types.Type, pkg *apiPackage) []*types.Type {
 
 	return sortTypes(constants)
 }
+
+const enumMarker = "kubebuilder:validation:Enum"
+
+// enumValue is one of the allowed values of an enum.
+type enumValue struct {
+	// Value is the value as written in manifests.
+	Value string
+	// Display is Value quoted for string enums, to make it clear to the
+	// documentation reader.
+	Display string
+	// Constant is the Go constant declaring the value, if there is one.
+	Constant *types.Type
+	// InMarker is true if the value is listed in the Enum marker.
+	InMarker bool
+}
+
+// enumModel merges the values listed in a "+kubebuilder:validation:Enum"
+// marker with the Go constants declared for the same type.
+type enumModel struct {
+	Values []enumValue
+	// HasMarker is true if the values come from an Enum marker, possibly in
+	// addition to constants.
+	HasMarker bool
+	// Mismatch is true if there are both a marker and constants and they do
+	// not list the same values.
+	Mismatch bool
+}
+
+// Allowed returns the values the API server accepts: the ones listed in the
+// marker if there is one, all of them otherwise.
+func (e *enumModel) Allowed() []enumValue {
+	if !e.HasMarker {
+		return e.Values
+	}
+	var out []enumValue
+	for _, v := range e.Values {
+		if v.InMarker {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (e *enumModel) markerValues() []string {
+	var out []string
+	for _, v := range e.Values {
+		if v.InMarker {
+			out = append(out, v.Value)
+		}
+	}
+	return out
+}
+
+func (e *enumModel) constantValues() []string {
+	var out []string
+	for _, v := range e.Values {
+		if v.Constant != nil {
+			out = append(out, v.Value)
+		}
+	}
+	return out
+}
+
+// newEnumModel merges the marker values with the constants of an enum of
+// type t. Values are listed in marker order, followed by constants that are
+// missing from the marker. It returns nil if there are no values at all.
+func newEnumModel(t *types.Type, marker string, hasMarker bool, constants []*types.Type) *enumModel {
+	e := &enumModel{HasMarker: hasMarker}
+	quote := func(v string) string { return v }
+	if u := finalUnderlyingTypeOf(t); u.Kind == types.Builtin && u.Name.Name == "string" {
+		quote = strconv.Quote
+	}
+
+	index := make(map[string]int)
+	if hasMarker {
+		for _, v := range markerList(marker) {
+			if _, ok := index[v]; ok {
+				continue
+			}
+			index[v] = len(e.Values)
+			e.Values = append(e.Values, enumValue{Value: v, Display: quote(v), InMarker: true})
+		}
+	}
+	for _, c := range constants {
+		if c.ConstValue == nil {
+			continue
+		}
+		v := *c.ConstValue
+		if i, ok := index[v]; ok {
+			if e.Values[i].Constant == nil {
+				e.Values[i].Constant = c
+			}
+			continue
+		}
+		index[v] = len(e.Values)
+		e.Values = append(e.Values, enumValue{Value: v, Display: quote(v), Constant: c})
+	}
+
+	if len(e.Values) == 0 {
+		return nil
+	}
+	if hasMarker && len(constants) > 0 {
+		for _, v := range e.Values {
+			if !v.InMarker || v.Constant == nil {
+				e.Mismatch = true
+			}
+		}
+	}
+	return e
+}
+
+// typeEnum returns the allowed values of type t, declared by an Enum marker
+// on t and/or constants of t in pkg. It returns nil if t is not an enum.
+func typeEnum(t *types.Type, pkg *apiPackage) *enumModel {
+	var constants []*types.Type
+	if pkg != nil {
+		constants = constantsOfType(t, pkg)
+	}
+	marker, hasMarker := lastMarkerValue(typeCommentLines(t), enumMarker)
+	return newEnumModel(t, marker, hasMarker, constants)
+}
+
+// memberEnum returns the allowed values of m. An Enum marker on the member
+// takes precedence over the one on its named type, but is still merged with
+// the constants of that type.
+func memberEnum(m types.Member, typePkgMap map[*types.Type]*apiPackage) *enumModel {
+	marker, hasMarker := lastMarkerValue(m.CommentLines, enumMarker)
+	t := namedMemberType(m)
+	if t == nil {
+		if !hasMarker {
+			return nil
+		}
+		return newEnumModel(tryDereference(m.Type), marker, hasMarker, nil)
+	}
+	if !hasMarker {
+		return typeEnum(t, typePkgMap[t])
+	}
+	var constants []*types.Type
+	if pkg := typePkgMap[t]; pkg != nil {
+		constants = constantsOfType(t, pkg)
+	}
+	return newEnumModel(t, marker, hasMarker, constants)
+}
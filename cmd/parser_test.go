@@ -0,0 +1,276 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/types"
+)
+
+// testMember returns the member name of type t, with the struct tags and
+// the comment lines.
+func testMember(name string, t *types.Type, tags string, comments ...string) types.Member {
+	return types.Member{Name: name, Type: t, Tags: tags, CommentLines: comments}
+}
+
+// testStruct returns the struct type WidgetSpec with the members ms.
+func testStruct(ms ...types.Member) *types.Type {
+	return &types.Type{
+		Name:    types.Name{Package: "example.com/api/v1", Name: "WidgetSpec"},
+		Kind:    types.Struct,
+		Members: ms,
+	}
+}
+
+// testKind returns a Kind with the extra markers.
+func testKind(name string, markers ...string) *types.Type {
+	return &types.Type{
+		Name:                      types.Name{Package: "example.com/api/v1", Name: name},
+		Kind:                      types.Struct,
+		SecondClosestCommentLines: append([]string{"+kubebuilder:object:root=true"}, markers...),
+	}
+}
+
+// testAlias returns the named type Port of underlying type u, with the
+// markers.
+func testAlias(u *types.Type, markers ...string) *types.Type {
+	return &types.Type{
+		Name:         types.Name{Package: "example.com/api/v1", Name: "Port"},
+		Kind:         types.Alias,
+		Underlying:   u,
+		CommentLines: markers,
+	}
+}
+
+func TestMemberValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		member types.Member
+		want   []validationRule
+	}{
+		{"no marker", testMember("Size", types.Int32, `json:"size"`, "Size is the size."), nil},
+		{
+			"markers in display order",
+			testMember("Size", types.Int32, `json:"size"`, "+kubebuilder:validation:Maximum=10", "+kubebuilder:validation:Minimum=1"),
+			[]validationRule{{"Minimum", "1"}, {"Maximum", "10"}},
+		},
+		{
+			"last marker wins",
+			testMember("Size", types.Int32, `json:"size"`, "+kubebuilder:validation:Minimum=1", "+kubebuilder:validation:Minimum=2"),
+			[]validationRule{{"Minimum", "2"}},
+		},
+		{
+			"quoted pattern",
+			testMember("Name", types.String, `json:"name"`, "+kubebuilder:validation:Pattern=`^[a-z]+$`"),
+			[]validationRule{{"Pattern", "^[a-z]+$"}},
+		},
+		{
+			"boolean marker",
+			testMember("Names", &types.Type{Kind: types.Slice, Elem: types.String}, `json:"names"`, "+kubebuilder:validation:UniqueItems"),
+			[]validationRule{{"UniqueItems", "true"}},
+		},
+		{
+			"unsupported marker",
+			testMember("Size", types.Int32, `json:"size"`, "+kubebuilder:validation:Foo=1", "+kubebuilder:validation:MinimumValue=1"),
+			nil,
+		},
+		{
+			"marker of the named type",
+			testMember("Port", testAlias(types.Int32, "+kubebuilder:validation:Minimum=1", "+kubebuilder:validation:Maximum=65535"), `json:"port"`),
+			[]validationRule{{"Minimum", "1"}, {"Maximum", "65535"}},
+		},
+		{
+			"member overrides the named type",
+			testMember("Port", testAlias(types.Int32, "+kubebuilder:validation:Minimum=1"), `json:"port"`, "+kubebuilder:validation:Minimum=1024"),
+			[]validationRule{{"Minimum", "1024"}},
+		},
+		{
+			"pointer to the named type",
+			testMember("Port", &types.Type{Kind: types.Pointer, Elem: testAlias(types.Int32, "+kubebuilder:validation:Minimum=1")}, `json:"port"`),
+			[]validationRule{{"Minimum", "1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := memberValidation(tt.member)
+			if tt.want == nil {
+				if v != nil {
+					t.Errorf("memberValidation() = %+v, want nil", v.Rules)
+				}
+				return
+			}
+			if v == nil || !reflect.DeepEqual(v.Rules, tt.want) {
+				t.Errorf("memberValidation() = %+v, want %+v", v, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemberDefault(t *testing.T) {
+	tests := []struct {
+		name   string
+		member types.Member
+		// want is the rendered default, "" for none
+		want    string
+		wantErr bool
+	}{
+		{"no marker", testMember("Size", types.Int32, `json:"size"`), "", false},
+		{"number", testMember("Size", types.Int32, `json:"size"`, "+kubebuilder:default=3"), "3", false},
+		{"string", testMember("Mode", types.String, `json:"mode"`, "+kubebuilder:default=Fast"), `"Fast"`, false},
+		{"quoted string", testMember("Mode", types.String, `json:"mode"`, `+kubebuilder:default="Fast"`), `"Fast"`, false},
+		{"object", testMember("Labels", types.String, `json:"labels"`, "+kubebuilder:default={a: b}"), `{"a":"b"}`, false},
+		{"list", testMember("Names", types.String, `json:"names"`, "+kubebuilder:default=[a, b]"), `["a","b"]`, false},
+		{"default marker", testMember("Size", types.Int32, `json:"size"`, "+default=4"), "4", false},
+		{"kubebuilder marker takes precedence", testMember("Size", types.Int32, `json:"size"`, "+kubebuilder:default=3", "+default=4"), "3", false},
+		{"last marker wins", testMember("Size", types.Int32, `json:"size"`, "+kubebuilder:default=3", "+kubebuilder:default=5"), "5", false},
+		{"marker of the named type", testMember("Port", testAlias(types.Int32, "+kubebuilder:default=80"), `json:"port"`), "80", false},
+		{"member overrides the named type", testMember("Port", testAlias(types.Int32, "+kubebuilder:default=80"), `json:"port"`, "+kubebuilder:default=8080"), "8080", false},
+		{"invalid literal", testMember("Labels", types.String, `json:"labels"`, "+kubebuilder:default={a: [}"), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := memberDefault(tt.member)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("memberDefault() error = %v, want error %v", err, tt.wantErr)
+			}
+			var got string
+			if d != nil {
+				got = d.String()
+			}
+			if got != tt.want {
+				t.Errorf("memberDefault() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeEnum(t *testing.T) {
+	mode := func(markers ...string) *types.Type {
+		return &types.Type{
+			Name:         types.Name{Package: "example.com/api/v1", Name: "Mode"},
+			Kind:         types.Alias,
+			Underlying:   types.String,
+			CommentLines: markers,
+		}
+	}
+	constant := func(t *types.Type, name, value string) *types.Type {
+		return &types.Type{Name: types.Name{Package: "example.com/api/v1", Name: name}, Kind: types.DeclarationOf, Underlying: t, ConstValue: &value}
+	}
+
+	tests := []struct {
+		name      string
+		t         *types.Type
+		constants func(t *types.Type) []*types.Type
+		// want are the displayed allowed values, nil if t is not an enum
+		want     []string
+		mismatch bool
+	}{
+		{"not an enum", mode(), nil, nil, false},
+		{
+			"constants",
+			mode(),
+			func(t *types.Type) []*types.Type {
+				return []*types.Type{constant(t, "ModeSlow", "Slow"), constant(t, "ModeFast", "Fast")}
+			},
+			[]string{`"Fast"`, `"Slow"`},
+			false,
+		},
+		{"marker", mode("+kubebuilder:validation:Enum=Slow;Fast;Slow"), nil, []string{`"Slow"`, `"Fast"`}, false},
+		{
+			"marker matching the constants",
+			mode("+kubebuilder:validation:Enum=Slow;Fast"),
+			func(t *types.Type) []*types.Type {
+				return []*types.Type{constant(t, "ModeFast", "Fast"), constant(t, "ModeSlow", "Slow")}
+			},
+			[]string{`"Slow"`, `"Fast"`},
+			false,
+		},
+		{
+			"marker not matching the constants",
+			mode("+kubebuilder:validation:Enum=Slow"),
+			func(t *types.Type) []*types.Type {
+				return []*types.Type{constant(t, "ModeFast", "Fast"), constant(t, "ModeSlow", "Slow")}
+			},
+			[]string{`"Slow"`},
+			true,
+		},
+		{"empty marker", mode("+kubebuilder:validation:Enum="), nil, nil, false},
+		{
+			"integer enum",
+			&types.Type{Name: types.Name{Package: "example.com/api/v1", Name: "Level"}, Kind: types.Alias, Underlying: types.Int32, CommentLines: []string{"+kubebuilder:validation:Enum=1;2"}},
+			nil,
+			[]string{"1", "2"},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := &apiPackage{apiGroup: "widgets.example.com", apiVersion: "v1", Types: []*types.Type{tt.t}}
+			if tt.constants != nil {
+				pkg.Constants = tt.constants(tt.t)
+			}
+			e := typeEnum(tt.t, pkg)
+			if tt.want == nil {
+				if e != nil {
+					t.Errorf("typeEnum() = %+v, want nil", e.Values)
+				}
+				return
+			}
+			if e == nil {
+				t.Fatalf("typeEnum() = nil, want %v", tt.want)
+			}
+			var got []string
+			for _, v := range e.Allowed() {
+				got = append(got, v.Display)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allowed values = %v, want %v", got, tt.want)
+			}
+			if e.Mismatch != tt.mismatch {
+				t.Errorf("mismatch = %v, want %v", e.Mismatch, tt.mismatch)
+			}
+		})
+	}
+}
+
+func TestMemberEnum(t *testing.T) {
+	mode := &types.Type{
+		Name:         types.Name{Package: "example.com/api/v1", Name: "Mode"},
+		Kind:         types.Alias,
+		Underlying:   types.String,
+		CommentLines: []string{"+kubebuilder:validation:Enum=Fast;Slow"},
+	}
+	fast := "Fast"
+	pkg := &apiPackage{
+		apiGroup:   "widgets.example.com",
+		apiVersion: "v1",
+		Types:      []*types.Type{mode},
+		Constants:  []*types.Type{{Name: types.Name{Package: "example.com/api/v1", Name: "ModeFast"}, Kind: types.DeclarationOf, Underlying: mode, ConstValue: &fast}},
+	}
+	typePkgMap := extractTypeToPackageMap([]*apiPackage{pkg})
+
+	tests := []struct {
+		name   string
+		member types.Member
+		want   []string
+	}{
+		{"builtin type", testMember("Name", types.String, `json:"name"`), nil},
+		{"marker on a builtin type", testMember("Name", types.String, `json:"name"`, "+kubebuilder:validation:Enum=a;b"), []string{`"a"`, `"b"`}},
+		{"enum type", testMember("Mode", mode, `json:"mode"`), []string{`"Fast"`, `"Slow"`}},
+		{"pointer to the enum type", testMember("Mode", &types.Type{Kind: types.Pointer, Elem: mode}, `json:"mode"`), []string{`"Fast"`, `"Slow"`}},
+		{"member marker overrides the type", testMember("Mode", mode, `json:"mode"`, "+kubebuilder:validation:Enum=Fast"), []string{`"Fast"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			if e := memberEnum(tt.member, typePkgMap); e != nil {
+				for _, v := range e.Allowed() {
+					got = append(got, v.Display)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("memberEnum() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
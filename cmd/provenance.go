@@ -0,0 +1,119 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+)
+
+// provenance records what the documentation was generated from, passed to
+// the templates as ".provenance".
+type provenance struct {
+	// Commit, Tag, Branch, Dirty and CommitDate describe the git checkout of
+	// the API. They are empty if it is not in a git repository, or if the
+	// config sets gitCommitDisabled.
+	Commit string `json:"commit,omitempty"`
+	// Tag is a tag of the commit, if any.
+	Tag string `json:"tag,omitempty"`
+	// Branch is empty for a detached HEAD.
+	Branch string `json:"branch,omitempty"`
+	// Dirty is true if the files of the API have uncommitted changes.
+	// Untracked files are not changes.
+	Dirty      bool   `json:"dirty,omitempty"`
+	CommitDate string `json:"commitDate,omitempty"`
+
+	// GeneratorVersion is the module version of the generator, empty for a
+	// development build.
+	GeneratorVersion string `json:"generatorVersion,omitempty"`
+	// Timestamp is when the documentation was generated, or the time given
+	// by SOURCE_DATE_EPOCH for reproducible builds. It defaults to the commit
+	// date of a clean checkout, so that regenerating it changes nothing.
+	Timestamp string `json:"timestamp"`
+	// ConfigHash is the SHA-256 of the config.
+	ConfigHash string `json:"configHash"`
+}
+
+// newProvenance returns the provenance of the documentation of the API in
+// dir generated with config.
+func newProvenance(dir string, config GeneratorConfig) (*provenance, error) {
+	p := &provenance{GeneratorVersion: generatorVersion()}
+	if !config.GitCommitDisabled {
+		if err := p.readGit(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	b, err := json.Marshal(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash the config")
+	}
+	sum := sha256.Sum256(b)
+	p.ConfigHash = hex.EncodeToString(sum[:])
+
+	t := time.Now()
+	if v, ok := os.LookupEnv("SOURCE_DATE_EPOCH"); ok {
+		sec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid SOURCE_DATE_EPOCH %q", v)
+		}
+		t = time.Unix(sec, 0)
+	} else if p.CommitDate != "" && !p.Dirty {
+		if t, err = time.Parse(time.RFC3339, p.CommitDate); err != nil {
+			return nil, errors.Wrap(err, "invalid commit date")
+		}
+	}
+	p.Timestamp = t.UTC().Format(time.RFC3339)
+	return p, nil
+}
+
+// readGit fills in the git checkout of dir, if it is in a git repository
+// with a commit.
+func (p *provenance) readGit(dir string) error {
+	if _, err := git(dir, "rev-parse", "--git-dir"); err != nil {
+		klog.Infof("%s is not in a git repository, not recording its commit", dir)
+		return nil
+	}
+	if _, err := git(dir, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		klog.Infof("the git repository of %s has no commit yet, not recording it", dir)
+		return nil
+	}
+	var err error
+	if p.Commit, err = git(dir, "rev-parse", "--short", "HEAD"); err != nil {
+		return err
+	}
+	if p.CommitDate, err = git(dir, "show", "-s", "--format=%cI", "HEAD"); err != nil {
+		return err
+	}
+	if p.Branch, err = git(dir, "rev-parse", "--abbrev-ref", "HEAD"); err != nil {
+		return err
+	}
+	if p.Branch == "HEAD" {
+		p.Branch = ""
+	}
+	// fails if no tag points at HEAD
+	p.Tag, _ = git(dir, "describe", "--tags", "--exact-match", "HEAD")
+	// only the changes to the files of the API matter
+	status, err := git(dir, "status", "--porcelain", "--untracked-files=no", "--", ".")
+	if err != nil {
+		return err
+	}
+	p.Dirty = status != ""
+	return nil
+}
+
+// generatorVersion returns the module version the generator was built
+// from, empty for a development build.
+func generatorVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "(devel)" {
+		return ""
+	}
+	return info.Main.Version
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// testGitRepo returns a git repository with a types.go file committed at
+// testCommitDate.
+func testGitRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "types.go"), "package v1\n")
+	testGit(t, dir, "init", "-q", "-b", "main")
+	testGit(t, dir, "add", "types.go")
+	testGit(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func TestNewProvenance(t *testing.T) {
+	epoch, invalidEpoch := "1600000000", "yesterday"
+	tests := []struct {
+		name       string
+		tag        string
+		dirty      bool
+		disableGit bool
+		epoch      *string
+		// timestamp is the expected timestamp, "" for the current time
+		timestamp string
+		wantErr   bool
+	}{
+		{name: "clean checkout", timestamp: testCommitDate},
+		{name: "tagged checkout", tag: "v1.2.0", timestamp: testCommitDate},
+		{name: "dirty checkout", dirty: true},
+		{name: "SOURCE_DATE_EPOCH", epoch: &epoch, timestamp: "2020-09-13T12:26:40Z"},
+		{name: "SOURCE_DATE_EPOCH of a dirty checkout", dirty: true, epoch: &epoch, timestamp: "2020-09-13T12:26:40Z"},
+		{name: "invalid SOURCE_DATE_EPOCH", epoch: &invalidEpoch, wantErr: true},
+		{name: "git disabled", disableGit: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := testGitRepo(t)
+			if tt.tag != "" {
+				if _, err := git(dir, "tag", tt.tag); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.dirty {
+				writeTestFile(t, filepath.Join(dir, "types.go"), "package v2\n")
+			}
+			setEnv(t, "SOURCE_DATE_EPOCH", tt.epoch)
+
+			p, err := newProvenance(dir, GeneratorConfig{GitCommitDisabled: tt.disableGit})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("newProvenance() = %+v, want an error", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.disableGit {
+				if p.Commit != "" || p.Branch != "" || p.CommitDate != "" {
+					t.Errorf("git checkout recorded: %+v", p)
+				}
+			} else {
+				if p.Commit == "" || p.Branch != "main" || p.Tag != tt.tag || p.Dirty != tt.dirty {
+					t.Errorf("got commit %q branch %q tag %q dirty %v, want a commit of main tagged %q dirty %v",
+						p.Commit, p.Branch, p.Tag, p.Dirty, tt.tag, tt.dirty)
+				}
+			}
+			if tt.timestamp != "" && p.Timestamp != tt.timestamp {
+				t.Errorf("timestamp = %s, want %s", p.Timestamp, tt.timestamp)
+			}
+			if tt.timestamp == "" && p.Timestamp == testCommitDate {
+				t.Errorf("timestamp = %s, want the current time", p.Timestamp)
+			}
+			if len(p.ConfigHash) != 64 {
+				t.Errorf("config hash = %q, want a SHA-256", p.ConfigHash)
+			}
+		})
+	}
+}
+
+func TestReadGitDirty(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(repo string)
+		dirty  bool
+	}{
+		{"clean checkout", func(string) {}, false},
+		{"API file modified", func(repo string) { writeTestFile(t, filepath.Join(repo, "api", "types.go"), "package v2\n") }, true},
+		{"API file staged", func(repo string) {
+			writeTestFile(t, filepath.Join(repo, "api", "types.go"), "package v2\n")
+			testGit(t, repo, "add", "api")
+		}, true},
+		{"untracked API file", func(repo string) { writeTestFile(t, filepath.Join(repo, "api", "new.go"), "package v1\n") }, false},
+		{"file outside the API modified", func(repo string) { writeTestFile(t, filepath.Join(repo, "types.go"), "package v2\n") }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := testGitRepo(t)
+			writeTestFile(t, filepath.Join(repo, "api", "types.go"), "package v1\n")
+			testGit(t, repo, "add", "api")
+			testGit(t, repo, "commit", "-q", "-m", "api")
+			tt.change(repo)
+
+			var p provenance
+			if err := p.readGit(filepath.Join(repo, "api")); err != nil {
+				t.Fatal(err)
+			}
+			if p.Dirty != tt.dirty {
+				t.Errorf("dirty = %v, want %v", p.Dirty, tt.dirty)
+			}
+		})
+	}
+}
+
+func TestReadGitWithoutCommit(t *testing.T) {
+	dir := t.TempDir()
+	testGit(t, dir, "init", "-q", "-b", "main")
+
+	var p provenance
+	if err := p.readGit(dir); err != nil {
+		t.Fatalf("readGit() = %v, want no error", err)
+	}
+	if p != (provenance{}) {
+		t.Errorf("readGit() recorded %+v, want nothing", p)
+	}
+}
@@ -5,13 +5,12 @@ import (
 	"fmt"
 	"html/template"
 	"io"
-	"os/exec"
-	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
 
+	refdocs "github.com/elastic/gen-crd-api-reference-docs"
 	"github.com/pkg/errors"
 	"k8s.io/gengo/types"
 	"k8s.io/klog"
@@ -19,12 +18,226 @@ import (
 	texttemplate "text/template"
 )
 
-func Render(w io.Writer, pkgs []*apiPackage, config GeneratorConfig) error {
-	references := findTypeReferences(pkgs)
-	typePkgMap := extractTypeToPackageMap(pkgs)
+const (
+	formatHTML     = "html"
+	formatMarkdown = "markdown"
+	formatAsciiDoc = "asciidoc"
+	formatJSON     = "json"
+	// formatJSONSchema writes a JSON Schema file per Kind, with -out-dir.
+	formatJSONSchema = "jsonschema"
+	// formatYAML writes an example manifest per Kind, with -out-dir.
+	formatYAML = "yaml"
+	// formatOpenAPI writes an OpenAPI v3 document with a schema per type.
+	formatOpenAPI = "openapi"
+)
+
+var (
+	// templateFormats are the formats rendered with templates.
+	templateFormats = []string{formatHTML, formatMarkdown, formatAsciiDoc}
+	formats         = append(templateFormats, formatJSON, formatJSONSchema, formatYAML, formatOpenAPI)
+
+	blankLinesRegex = regexp.MustCompile(`\n{3,}`)
+)
+
+// templateExecutor is implemented by both html/template and text/template.
+type templateExecutor interface {
+	ExecuteTemplate(w io.Writer, name string, data interface{}) error
+}
+
+// renderer renders API packages with the templates of a format.
+type renderer struct {
+	format       string
+	config       GeneratorConfig
+	pkgs         []*apiPackage
+	references   map[*types.Type][]*types.Type
+	typePkgMap   map[*types.Type]*apiPackage
+	groups       []*groupVersions
+	kindVersions map[*types.Type]*kindVersion
+	// examples are the hand-written examples of each type.
+	examples map[*types.Type][]*example
+	// memberOrigins are the inline structs the flattened members of each
+	// type are declared in, see memberOrigin.
+	memberOrigins map[*types.Type]map[memberKey]*types.Type
+	// layout is set when rendering multiple pages.
+	layout *siteLayout
+	// templates are the parsed templates, once a page is rendered.
+	templates templateExecutor
+	// provenance is what the documentation is generated from, once known.
+	provenance *provenance
+	// sourceLinkTemplate is the parsed sourceLinkTemplate of the config, nil
+	// if there are no links to the source.
+	sourceLinkTemplate *texttemplate.Template
+}
+
+func newRenderer(pkgs []*apiPackage, config GeneratorConfig, format string) *renderer {
+	groups, kindVersions := buildGroupVersions(pkgs, config)
+	return &renderer{
+		format:        format,
+		config:        config,
+		pkgs:          pkgs,
+		references:    findTypeReferences(pkgs),
+		typePkgMap:    extractTypeToPackageMap(pkgs),
+		groups:        groups,
+		kindVersions:  kindVersions,
+		memberOrigins: make(map[*types.Type]map[memberKey]*types.Type),
+	}
+}
+
+// Render renders the documentation of pkgs as a single page. prov is what
+// the documentation is generated from.
+func Render(w io.Writer, pkgs []*apiPackage, config GeneratorConfig, prov *provenance) error {
+	warnInvalidMarkers(pkgs)
+	r := newRenderer(pkgs, config, outputFormat())
+	r.provenance = prov
+	if err := r.loadExamples(); err != nil {
+		return err
+	}
+	if err := r.loadSourceLinks(); err != nil {
+		return err
+	}
+	switch r.format {
+	case formatJSON:
+		return r.writeDocModel(w, prov)
+	case formatOpenAPI:
+		return r.writeOpenAPI(w, prov)
+	case formatJSONSchema, formatYAML:
+		return errors.Errorf("the %s format writes a file per Kind and requires -out-dir", r.format)
+	}
+	var b bytes.Buffer
+	if err := r.render(&b, &page{Kind: pageSingle, Title: "API Reference", Packages: pkgs}, prov); err != nil {
+		return err
+	}
+	out := map[string]string{"": b.String()}
+	r.headingAnchors(out)
+	_, err := io.WriteString(w, out[""])
+	return errors.Wrap(err, "failed to write the result")
+}
+
+// RenderSite renders the documentation of pkgs as an index page and a page
+// per API package, plus a page per Kind if kindPages is set, like Render. It
+// returns the content of every page by path.
+func RenderSite(pkgs []*apiPackage, config GeneratorConfig, kindPages bool, prov *provenance) (map[string]string, error) {
+	warnInvalidMarkers(pkgs)
+	r := newRenderer(pkgs, config, outputFormat())
+	r.layout = newSiteLayout(r.format, kindPages, config, r.typePkgMap)
+	r.provenance = prov
+	if err := r.loadExamples(); err != nil {
+		return nil, err
+	}
+	if err := r.loadSourceLinks(); err != nil {
+		return nil, err
+	}
+	switch r.format {
+	case formatJSONSchema:
+		return r.jsonSchemas()
+	case formatYAML:
+		return r.kindExamples(), nil
+	}
+	if !containsString(templateFormats, r.format) {
+		return nil, errors.Errorf("the %s format cannot be split into pages", r.format)
+	}
+	if config.SiteGenerator != nil && r.format != formatMarkdown {
+		return nil, errors.Errorf("siteGenerator requires the %s format", formatMarkdown)
+	}
+	pages := r.layout.pages(pkgs)
+	out := make(map[string]string)
+	for _, p := range pages {
+		var b bytes.Buffer
+		if err := r.render(&b, p, prov); err != nil {
+			return nil, errors.Wrapf(err, "failed to render %s", p.Path)
+		}
+		out[p.Path] = b.String()
+	}
+	r.headingAnchors(out)
+	if config.SiteGenerator != nil {
+		if err := config.SiteGenerator.decorate(out, pages); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// headingAnchors replaces the {#id} heading attributes of the Markdown pages
+// with the anchors GitHub generates, unless the config keeps them.
+func (r *renderer) headingAnchors(pages map[string]string) {
+	if r.format == formatMarkdown && !r.config.MarkdownHeadingIDs && r.config.SiteGenerator == nil {
+		githubHeadingAnchors(pages)
+	}
+}
+
+func (r *renderer) render(w io.Writer, p *page, prov *provenance) error {
+	var links *pageLinker
+	var siteGenerator string
+	if r.layout != nil {
+		links = &pageLinker{layout: r.layout, current: p}
+		if sg := r.config.SiteGenerator; sg != nil {
+			siteGenerator = sg.Name
+		}
+	}
+	// the templates are parsed once, and cloned for the links of every page
+	if r.templates == nil {
+		t, err := parseTemplates(r.format, r.templateFuncs(nil))
+		if err != nil {
+			return err
+		}
+		r.templates = t
+	}
+	t := r.templates
+	if links != nil {
+		var err error
+		if t, err = cloneTemplates(t, r.linkFuncs(links)); err != nil {
+			return err
+		}
+	}
 
-	t, err := template.New("").Funcs(map[string]interface{}{
+	return errors.Wrap(t.ExecuteTemplate(w, "page", map[string]interface{}{
+		"packages":      r.pkgs,
+		"groups":        r.groups,
+		"page":          p,
+		"siteGenerator": siteGenerator,
+		"config":        r.config,
+		"gitCommit":     prov.Commit,
+		"provenance":    prov,
+	}), "template execution error")
+}
+
+// linkFuncs returns the functions of the templates producing the links of
+// the page links is for.
+func (r *renderer) linkFuncs(links *pageLinker) map[string]interface{} {
+	config, typePkgMap := r.config, r.typePkgMap
+	return map[string]interface{}{
+		"linkForPackage": func(p *apiPackage, anchor string) string { return links.packageLink(p, anchor) },
+		"linkForIndex": func() string {
+			if links == nil {
+				return ""
+			}
+			return links.link(links.layout.indexPage(), "")
+		},
+		"pageTypes": func(p *apiPackage) []*types.Type { return links.pageTypes(p, config) },
+		"linkForType": func(t *types.Type) (string, error) {
+			v, err := linkForType(t, config, typePkgMap, links)
+			if err != nil {
+				return "", errors.Wrapf(err, "error getting link for type=%s", t.Name)
+			}
+			return v, nil
+		},
+		"asciidocLinkForType": func(t *types.Type) (string, error) {
+			link, err := linkForType(t, config, typePkgMap, links)
+			if err != nil {
+				return "", errors.Wrapf(err, "error getting link for type=%s", t.Name)
+			}
+			return asciidocLink(typeDisplayName(t, config, typePkgMap), link), nil
+		},
+	}
+}
+
+// templateFuncs returns the functions available to the templates, producing
+// the links of the page links is for.
+func (r *renderer) templateFuncs(links *pageLinker) map[string]interface{} {
+	config, typePkgMap, references, kindVersions := r.config, r.typePkgMap, r.references, r.kindVersions
+	funcs := map[string]interface{}{
 		"isExportedType":     isExportedType,
+		"isListType":         isListType,
 		"fieldName":          fieldName,
 		"fieldEmbedded":      fieldEmbedded,
 		"typeIdentifier":     func(t *types.Type) string { return typeIdentifier(t) },
@@ -37,52 +250,64 @@ func Render(w io.Writer, pkgs []*apiPackage, config GeneratorConfig) error {
 			// space trimmed displayName
 			return strings.Replace(p.identifier(), " ", "", -1)
 		},
-		"linkForType": func(t *types.Type) string {
-			v, err := linkForType(t, config, typePkgMap)
-			if err != nil {
-				klog.Fatal(errors.Wrapf(err, "error getting link for type=%s", t.Name))
-				return ""
-			}
-			return v
-		},
-		"asciidocLinkForType": func(t *types.Type) string {
-			link, err := linkForType(t, config, typePkgMap)
-			if err != nil {
-				klog.Fatal(errors.Wrapf(err, "error getting link for type=%s", t.Name))
-				return ""
-			}
-
-			displayName := typeDisplayName(t, config, typePkgMap)
-
-			if strings.HasPrefix(link, "#") {
-				return fmt.Sprintf("xref:%s[$$%s$$]", strings.TrimPrefix(link, "#"), displayName)
-			}
-			return fmt.Sprintf("link:%s[$$%s$$]", link, displayName)
-		},
 		"anchorIDForType":  func(t *types.Type) string { return anchorIDForLocalType(t, typePkgMap) },
 		"safe":             safe,
 		"sortedTypes":      sortTypes,
 		"typeReferences":   func(t *types.Type) []*types.Type { return typeReferences(t, config, references) },
 		"hiddenMember":     func(m types.Member) bool { return hiddenMember(m, config) },
+		"typeMembers":      func(t interface{}) []types.Member { return typeMembers(tableOf(t).Type, config) },
+		"memberOrigin":     func(t interface{}, m types.Member) *types.Type { return r.memberOrigin(tableOf(t).Type, m) },
+		"expandMember":     func(t interface{}, m types.Member) *memberExpansion { return expandMember(tableOf(t), m, config) },
+		"memberPath":       func(t interface{}, m types.Member) string { return memberPath(tableOf(t), m) },
 		"isLocalType":      isLocalType,
 		"isOptionalMember": isOptionalMember,
-		"safeIdentifier":   safeIdentifier,
-		"constantsOfType":  func(t *types.Type) []*types.Type { return constantsOfType(t, typePkgMap[t]) },
-	}).ParseGlob(filepath.Join(*flTemplateDir, "*.tpl"))
-	if err != nil {
-		return errors.Wrap(err, "parse error")
+		"memberValidation": memberValidation,
+		"memberDefault": func(m types.Member) *fieldDefault {
+			d, _ := memberDefault(m) // reported by warnInvalidMarkers
+			return d
+		},
+		"safeIdentifier":  safeIdentifier,
+		"constantsOfType": func(t *types.Type) []*types.Type { return constantsOfType(t, typePkgMap[t]) },
+		"typeEnum":        func(t *types.Type) *enumModel { return typeEnum(t, typePkgMap[t]) },
+		"memberEnum":      func(m types.Member) *enumModel { return memberEnum(m, typePkgMap) },
+		"resourceInfo":    resourceForType,
+		"kindExample":     r.kindExample,
+		"typeExamples":    func(t *types.Type) []*example { return r.examples[t] },
+		"kindVersion":     func(t *types.Type) *kindVersion { return kindVersions[t] },
+		"typeSourceLink":  func(t *types.Type) (string, error) { return r.sourceLink(r.typeSource(t)) },
+		"memberSourceLink": func(v interface{}, m types.Member) (string, error) {
+			t := tableOf(v).Type
+			if from := r.memberOrigin(t, m); from != nil {
+				t = from
+			}
+			return r.sourceLink(r.memberSource(t, m))
+		},
+		"compareKind": func(k *groupKind, versions []string) *kindComparison {
+			return compareKind(k, versions, config, typePkgMap)
+		},
+		"markdownEscape": markdownEscape,
+		"markdownCode":   markdownCode,
+		"markdownLink":   markdownLink,
+		"markdownCell":   markdownCell,
+		"asciidocEscape": asciidocEscape,
+		"asciidocCode":   asciidocCode,
+		"asciidocCell":   asciidocCell,
+		"asciidocLink":   asciidocLink,
+		"stylesheet":     func() template.CSS { return template.CSS(refdocs.Stylesheet) },
 	}
 
-	var gitCommit []byte
-	if !config.GitCommitDisabled {
-		gitCommit, _ = exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	for name, f := range r.linkFuncs(links) {
+		funcs[name] = f
 	}
 
-	return errors.Wrap(t.ExecuteTemplate(w, "page", map[string]interface{}{
-		"packages":  pkgs,
-		"config":    config,
-		"gitCommit": strings.TrimSpace(string(gitCommit)),
-	}), "template execution error")
+	switch r.format {
+	case formatMarkdown:
+		// comments are kept as Markdown instead of being rendered to HTML
+		funcs["renderComments"] = func(s []string) string { return renderMarkdownComments(s, !config.MarkdownDisabled) }
+	case formatAsciiDoc:
+		funcs["renderComments"] = func(s []string) string { return renderAsciiDocComments(s, !config.MarkdownDisabled) }
+	}
+	return funcs
 }
 
 // anchorIDForLocalType returns the #anchor string for the local type
@@ -123,12 +348,13 @@ func findTypeReferences(pkgs []*apiPackage) map[*types.Type][]*types.Type {
 }
 
 // linkForType returns an anchor to the type if it can be generated. returns
-// empty string if it is not a local type or unrecognized external type.
-func linkForType(t *types.Type, c GeneratorConfig, typePkgMap map[*types.Type]*apiPackage) (string, error) {
+// empty string if it is not a local type or unrecognized external type. Links
+// to local types are relative to the page links is for.
+func linkForType(t *types.Type, c GeneratorConfig, typePkgMap map[*types.Type]*apiPackage, links *pageLinker) (string, error) {
 	t = tryDereference(t) // dereference kind=Pointer
 
 	if isLocalType(t, typePkgMap) {
-		return "#" + anchorIDForLocalType(t, typePkgMap), nil
+		return links.typeLink(t, anchorIDForLocalType(t, typePkgMap)), nil
 	}
 
 	var arrIndex = func(a []string, i int) string {
@@ -279,3 +505,16 @@ func visibleTypes(in []*types.Type, c GeneratorConfig) []*types.Type {
 	}
 	return out
 }
+
+// tidyText removes the trailing whitespace and runs of empty lines that
+// template actions leave behind, without touching the leading indentation
+// Markdown and AsciiDoc are sensitive to.
+func tidyText(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t")
+	}
+	s = strings.Join(lines, "\n")
+	s = blankLinesRegex.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s) + "\n"
+}
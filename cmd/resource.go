@@ -0,0 +1,130 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"k8s.io/gengo/types"
+)
+
+const (
+	scopeNamespaced = "Namespaced"
+	scopeCluster    = "Cluster"
+)
+
+// resourceInfo describes how a Kind is served by the API server, as declared
+// by its kubebuilder and genclient markers.
+type resourceInfo struct {
+	// Scope is either "Namespaced" or "Cluster".
+	Scope      string   `json:"scope"`
+	Plural     string   `json:"plural"`
+	Singular   string   `json:"singular"`
+	ShortNames []string `json:"shortNames,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+
+	// StatusSubresource is true if the Kind has a /status subresource.
+	StatusSubresource bool `json:"statusSubresource"`
+	// Scale is set if the Kind has a /scale subresource.
+	Scale *scaleSubresource `json:"scale,omitempty"`
+
+	PrinterColumns []printerColumn `json:"printerColumns,omitempty"`
+}
+
+// Namespaced reports whether objects of the Kind live in a namespace.
+func (r *resourceInfo) Namespaced() bool { return r.Scope != scopeCluster }
+
+// scaleSubresource holds the arguments of "+kubebuilder:subresource:scale".
+type scaleSubresource struct {
+	SpecPath     string `json:"specPath"`
+	StatusPath   string `json:"statusPath"`
+	SelectorPath string `json:"selectorPath,omitempty"`
+}
+
+// printerColumn is a column shown by "kubectl get", declared with
+// "+kubebuilder:printcolumn".
+type printerColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	JSONPath    string `json:"jsonPath"`
+	Description string `json:"description,omitempty"`
+	Format      string `json:"format,omitempty"`
+	// Priority is 0 for columns shown by default, and higher for the ones
+	// only shown with "kubectl get -o wide".
+	Priority int `json:"priority"`
+}
+
+// resourceForType parses the resource markers of the Kind t. It returns nil if
+// t is not a Kind, or is the list type of a Kind.
+func resourceForType(t *types.Type) *resourceInfo {
+	if !isExportedType(t) || isListType(t) {
+		return nil
+	}
+	lines := typeCommentLines(t)
+
+	kind := t.Name.Name
+	r := &resourceInfo{
+		Scope:    scopeNamespaced,
+		Plural:   pluralize(strings.ToLower(kind)),
+		Singular: strings.ToLower(kind),
+	}
+	if hasMarker(lines, "genclient:nonNamespaced") {
+		r.Scope = scopeCluster
+	}
+
+	for _, v := range markerValues(lines, "kubebuilder:resource") {
+		args := markerArgs(v)
+		if scope, ok := args["scope"]; ok {
+			r.Scope = scope
+		}
+		if path, ok := args["path"]; ok {
+			r.Plural = path
+		}
+		if singular, ok := args["singular"]; ok {
+			r.Singular = singular
+		}
+		if shortNames, ok := args["shortName"]; ok {
+			r.ShortNames = markerList(shortNames)
+		}
+		if categories, ok := args["categories"]; ok {
+			r.Categories = markerList(categories)
+		}
+	}
+
+	r.StatusSubresource = hasMarker(lines, "kubebuilder:subresource:status")
+	if v, ok := lastMarkerValue(lines, "kubebuilder:subresource:scale"); ok {
+		args := markerArgs(v)
+		r.Scale = &scaleSubresource{
+			SpecPath:     args["specpath"],
+			StatusPath:   args["statuspath"],
+			SelectorPath: args["selectorpath"],
+		}
+	}
+
+	for _, v := range markerValues(lines, "kubebuilder:printcolumn") {
+		args := markerArgs(v)
+		priority, _ := strconv.Atoi(args["priority"])
+		r.PrinterColumns = append(r.PrinterColumns, printerColumn{
+			Name:        args["name"],
+			Type:        args["type"],
+			JSONPath:    args["JSONPath"],
+			Description: args["description"],
+			Format:      args["format"],
+			Priority:    priority,
+		})
+	}
+	return r
+}
+
+// pluralize guesses the plural resource name for a lowercased Kind the way
+// controller-gen does for the common English cases. Kinds with irregular
+// plurals should declare "+kubebuilder:resource:path".
+func pluralize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "z"),
+		strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsAny(s[len(s)-2:len(s)-1], "aeiou"):
+		return s[:len(s)-1] + "ies"
+	}
+	return s + "s"
+}
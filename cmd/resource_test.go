@@ -0,0 +1,93 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/types"
+)
+
+func TestPluralize(t *testing.T) {
+	for kind, want := range map[string]string{
+		"widget":  "widgets",
+		"class":   "classes",
+		"box":     "boxes",
+		"quiz":    "quizes",
+		"patch":   "patches",
+		"mesh":    "meshes",
+		"policy":  "policies",
+		"gateway": "gateways",
+		"y":       "ys",
+	} {
+		if got := pluralize(kind); got != want {
+			t.Errorf("pluralize(%q) = %q, want %q", kind, got, want)
+		}
+	}
+}
+
+func TestResourceForType(t *testing.T) {
+	list := testKind("WidgetList")
+	list.Members = []types.Member{testMember("Items", &types.Type{Kind: types.Slice, Elem: testKind("Widget")}, `json:"items"`)}
+
+	tests := []struct {
+		name string
+		t    *types.Type
+		want *resourceInfo
+	}{
+		{"not a Kind", testStruct(), nil},
+		{"list Kind", list, nil},
+		{
+			"defaults",
+			testKind("Policy"),
+			&resourceInfo{Scope: scopeNamespaced, Plural: "policies", Singular: "policy"},
+		},
+		{
+			"genclient cluster scope",
+			testKind("Widget", "+genclient:nonNamespaced"),
+			&resourceInfo{Scope: scopeCluster, Plural: "widgets", Singular: "widget"},
+		},
+		{
+			"resource marker",
+			testKind("Widget", "+kubebuilder:resource:scope=Cluster,path=widgetz,singular=wdgt,shortName=wd;w,categories=all"),
+			&resourceInfo{Scope: scopeCluster, Plural: "widgetz", Singular: "wdgt", ShortNames: []string{"wd", "w"}, Categories: []string{"all"}},
+		},
+		{
+			"subresources",
+			testKind("Widget", "+kubebuilder:subresource:status", "+kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas"),
+			&resourceInfo{
+				Scope: scopeNamespaced, Plural: "widgets", Singular: "widget",
+				StatusSubresource: true,
+				Scale:             &scaleSubresource{SpecPath: ".spec.replicas", StatusPath: ".status.replicas"},
+			},
+		},
+		{
+			"printer columns",
+			testKind("Widget",
+				`+kubebuilder:printcolumn:name="Size",type=integer,JSONPath=".spec.size",description="The size, in units"`,
+				`+kubebuilder:printcolumn:name=Age,type=date,JSONPath=.metadata.creationTimestamp,priority=1`,
+			),
+			&resourceInfo{
+				Scope: scopeNamespaced, Plural: "widgets", Singular: "widget",
+				PrinterColumns: []printerColumn{
+					{Name: "Size", Type: "integer", JSONPath: ".spec.size", Description: "The size, in units"},
+					{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp", Priority: 1},
+				},
+			},
+		},
+		{
+			"invalid priority",
+			testKind("Widget", `+kubebuilder:printcolumn:name=Age,type=date,JSONPath=.metadata.creationTimestamp,priority=high`),
+			&resourceInfo{
+				Scope: scopeNamespaced, Plural: "widgets", Singular: "widget",
+				PrinterColumns: []printerColumn{{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resourceForType(tt.t); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resourceForType() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
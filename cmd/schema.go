@@ -0,0 +1,435 @@
+package main
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/gengo/types"
+	"k8s.io/klog"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// jsonSchema is the subset of JSON Schema (draft-07) and of the OpenAPI v3
+// schema object the generated schemas use.
+type jsonSchema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Format      string `json:"format,omitempty"`
+
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AllOf                []*jsonSchema          `json:"allOf,omitempty"`
+	AnyOf                []*jsonSchema          `json:"anyOf,omitempty"`
+
+	Enum    []interface{} `json:"enum,omitempty"`
+	Default interface{}   `json:"default,omitempty"`
+
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+	// ExclusiveMinimum and ExclusiveMaximum are numbers in JSON Schema, and
+	// booleans qualifying Minimum and Maximum in OpenAPI v3.0.
+	ExclusiveMinimum interface{} `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum interface{} `json:"exclusiveMaximum,omitempty"`
+	MultipleOf       *float64    `json:"multipleOf,omitempty"`
+	MinLength        *int64      `json:"minLength,omitempty"`
+	MaxLength        *int64      `json:"maxLength,omitempty"`
+	Pattern          string      `json:"pattern,omitempty"`
+	MinItems         *int64      `json:"minItems,omitempty"`
+	MaxItems         *int64      `json:"maxItems,omitempty"`
+	UniqueItems      bool        `json:"uniqueItems,omitempty"`
+	MinProperties    *int64      `json:"minProperties,omitempty"`
+	MaxProperties    *int64      `json:"maxProperties,omitempty"`
+
+	XIntOrString           bool                `json:"x-kubernetes-int-or-string,omitempty"`
+	XPreserveUnknownFields bool                `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	XGroupVersionKind      []*groupVersionKind `json:"x-kubernetes-group-version-kind,omitempty"`
+
+	Definitions map[string]*jsonSchema `json:"definitions,omitempty"`
+}
+
+type groupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// wellKnownSchemas are the schemas of the Kubernetes types that serialize to
+// something else than their Go structure, keyed by typeIdentifier.
+var wellKnownSchemas = map[string]func() *jsonSchema{
+	"k8s.io/apimachinery/pkg/api/resource.Quantity":   intOrStringSchema,
+	"k8s.io/apimachinery/pkg/util/intstr.IntOrString": intOrStringSchema,
+	"k8s.io/apimachinery/pkg/apis/meta/v1.Time": func() *jsonSchema {
+		return &jsonSchema{Type: "string", Format: "date-time"}
+	},
+	"k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime": func() *jsonSchema {
+		return &jsonSchema{Type: "string", Format: "date-time"}
+	},
+	"k8s.io/apimachinery/pkg/apis/meta/v1.Duration": func() *jsonSchema {
+		return &jsonSchema{Type: "string"}
+	},
+	"k8s.io/apimachinery/pkg/runtime.RawExtension": func() *jsonSchema {
+		return &jsonSchema{Type: "object", XPreserveUnknownFields: true}
+	},
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1.JSON":      anyJSONSchema,
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1.JSON": anyJSONSchema,
+}
+
+// anyJSONSchema is the schema of apiextensions JSON, which holds any value.
+func anyJSONSchema() *jsonSchema {
+	return &jsonSchema{XPreserveUnknownFields: true}
+}
+
+func intOrStringSchema() *jsonSchema {
+	return &jsonSchema{
+		AnyOf:        []*jsonSchema{{Type: "integer"}, {Type: "string"}},
+		XIntOrString: true,
+	}
+}
+
+// schemaBuilder builds the schemas of Go types. Local struct types are added
+// to definitions once and referred to with refPrefix, which also takes care
+// of recursive types.
+type schemaBuilder struct {
+	r           *renderer
+	refPrefix   string
+	definitions map[string]*jsonSchema
+	// openAPI selects the OpenAPI v3.0 flavor of the schema keywords.
+	openAPI bool
+	// externalRef returns the schema of an external struct type that is
+	// not well-known. It may return nil, for an opaque object.
+	externalRef func(t *types.Type) *jsonSchema
+}
+
+func newSchemaBuilder(r *renderer, refPrefix string) *schemaBuilder {
+	return &schemaBuilder{r: r, refPrefix: refPrefix, definitions: make(map[string]*jsonSchema)}
+}
+
+// componentNameInvalidChars are the characters OpenAPI does not allow in the
+// names of components.
+var componentNameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9.\-_]`)
+
+// definitionName is the key of t in the definitions: its typeIdentifier with
+// the characters components cannot have, such as "/", replaced by "_" (e.g.
+// "example.com_api_widgets_v1.Widget" for example.com/api/widgets/v1.Widget).
+func definitionName(t *types.Type) string {
+	return componentNameInvalidChars.ReplaceAllString(typeIdentifier(t), "_")
+}
+
+// ref returns a reference to the definition of the local struct type t,
+// adding it to the definitions if needed.
+func (b *schemaBuilder) ref(t *types.Type) *jsonSchema {
+	name := definitionName(t)
+	if _, ok := b.definitions[name]; !ok {
+		b.definitions[name] = nil // guards against recursion
+		b.definitions[name] = b.structSchema(t)
+	}
+	return b.refTo(t)
+}
+
+// refTo returns a reference to the definition of t.
+func (b *schemaBuilder) refTo(t *types.Type) *jsonSchema {
+	// the name has no characters to escape in a JSON pointer
+	return &jsonSchema{Ref: b.refPrefix + definitionName(t)}
+}
+
+// typeSchema returns the schema of a value of type t.
+func (b *schemaBuilder) typeSchema(t *types.Type) *jsonSchema {
+	if t.Kind == types.Pointer {
+		return b.typeSchema(t.Elem)
+	}
+	if f, ok := wellKnownSchemas[typeIdentifier(t)]; ok && t.Kind != types.Slice && t.Kind != types.Map {
+		return f()
+	}
+
+	switch t.Kind {
+	case types.Builtin:
+		return builtinSchema(t)
+	case types.Alias:
+		if b.openAPI && isLocalType(t, b.r.typePkgMap) && !hideType(t, b.r.config) {
+			// the component of the alias, see writeOpenAPI
+			return b.refTo(t)
+		}
+		return b.typeSchema(t.Underlying)
+	case types.Slice, types.Array:
+		if e := t.Elem; e.Kind == types.Builtin && e.Name.Name == "byte" {
+			return &jsonSchema{Type: "string", Format: "byte"}
+		}
+		return &jsonSchema{Type: "array", Items: b.typeSchema(t.Elem)}
+	case types.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: b.typeSchema(t.Elem)}
+	case types.Struct:
+		if isLocalType(t, b.r.typePkgMap) {
+			if b.openAPI && hideType(t, b.r.config) {
+				// hidden types have no component
+				return &jsonSchema{Type: "object", XPreserveUnknownFields: true}
+			}
+			return b.ref(t)
+		}
+		if b.externalRef != nil {
+			if s := b.externalRef(t); s != nil {
+				return s
+			}
+		}
+		return &jsonSchema{Type: "object", XPreserveUnknownFields: true}
+	case types.Interface:
+		return &jsonSchema{XPreserveUnknownFields: true}
+	}
+	klog.Warningf("type %s has kind=%v which has no schema", t.Name, t.Kind)
+	return &jsonSchema{}
+}
+
+func builtinSchema(t *types.Type) *jsonSchema {
+	switch name := t.Name.Name; {
+	case name == "string":
+		return &jsonSchema{Type: "string"}
+	case name == "bool":
+		return &jsonSchema{Type: "boolean"}
+	case name == "int32" || name == "int16" || name == "int8" || name == "uint16" || name == "uint8" || name == "byte":
+		return &jsonSchema{Type: "integer", Format: "int32"}
+	case strings.HasPrefix(name, "int") || strings.HasPrefix(name, "uint"):
+		// int and uint are 64 bits wide on 64-bit platforms, and uint32
+		// overflows int32
+		return &jsonSchema{Type: "integer", Format: "int64"}
+	case name == "float32":
+		return &jsonSchema{Type: "number", Format: "float"}
+	case name == "float64":
+		return &jsonSchema{Type: "number", Format: "double"}
+	}
+	klog.Warningf("builtin type %s has no schema", t.Name)
+	return &jsonSchema{}
+}
+
+// structSchema returns the schema of the members of the struct type t.
+func (b *schemaBuilder) structSchema(t *types.Type) *jsonSchema {
+	s := &jsonSchema{
+		Type:        "object",
+		Description: commentText(t.CommentLines),
+		Properties:  make(map[string]*jsonSchema),
+	}
+	b.addMembers(s, t)
+	return s
+}
+
+// addMembers adds the members of t to the properties of s, flattening the
+// members of embedded structs.
+func (b *schemaBuilder) addMembers(s *jsonSchema, t *types.Type) {
+	for _, f := range flattenMembers(t, b.r.config) {
+		name := fieldName(f.Member)
+		s.Properties[name] = b.memberSchema(f.Member)
+		if isRequiredMember(f.Member) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// isRequiredMember reports whether m must be set. Like controller-gen, the
+// +kubebuilder:validation:Optional and Required markers take precedence over
+// +optional and +required, and unmarked fields are required unless they are
+// omitted when empty.
+func isRequiredMember(m types.Member) bool {
+	switch {
+	case hasMarker(m.CommentLines, "kubebuilder:validation:Optional"):
+		return false
+	case hasMarker(m.CommentLines, "kubebuilder:validation:Required"):
+		return true
+	case isOptionalMember(m):
+		return false
+	case hasMarker(m.CommentLines, "required"):
+		return true
+	}
+	return !strings.Contains(reflect.StructTag(m.Tags).Get("json"), ",omitempty")
+}
+
+// memberSchema returns the schema of the member m, with its description,
+// default value, enum values and validation constraints.
+func (b *schemaBuilder) memberSchema(m types.Member) *jsonSchema {
+	s := b.typeSchema(m.Type)
+	description := commentText(m.CommentLines)
+	d, _ := memberDefault(m) // reported by warnInvalidMarkers
+	e := memberEnum(m, b.r.typePkgMap)
+	v := memberValidation(m)
+	if s.Ref != "" && namedMemberType(m) != nil {
+		// the schema of a local alias type has its markers, only those of
+		// the member are added
+		d = nil
+		if literal, ok := defaultMarkerValue(m.CommentLines); ok {
+			d, _ = decodeDefault(literal)
+		}
+		if !hasMarker(m.CommentLines, enumMarker) {
+			e = nil
+		}
+		v = markerValidation(m.CommentLines)
+	}
+	if s.Ref != "" {
+		if description == "" && d == nil && e == nil && v == nil {
+			return s
+		}
+		// keywords next to a $ref are ignored
+		s = &jsonSchema{AllOf: []*jsonSchema{s}}
+	}
+	s.Description = description
+	if d != nil {
+		s.Default = d.Value
+	}
+
+	// validation markers of list fields apply to the list, except for the
+	// enum which applies to the items
+	target := s
+	if s.Type == "array" && s.Items != nil {
+		if s.Items.Ref != "" && e != nil {
+			s.Items = &jsonSchema{AllOf: []*jsonSchema{s.Items}}
+		}
+		target = s.Items
+	}
+	if e != nil {
+		for _, v := range e.Allowed() {
+			target.Enum = append(target.Enum, enumSchemaValue(target.Type, v.Value))
+		}
+	}
+	if v != nil {
+		b.applyValidation(s, v)
+	}
+	return s
+}
+
+func enumSchemaValue(typ, v string) interface{} {
+	switch typ {
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+// applyValidation sets the validation keywords of the kubebuilder markers.
+// Values that cannot be parsed are logged and skipped.
+func (b *schemaBuilder) applyValidation(s *jsonSchema, v *fieldValidation) {
+	number := func(marker string) *float64 {
+		value, ok := v.Get(marker)
+		if !ok {
+			return nil
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			klog.Warningf("invalid +%s%s value %q", validationMarkerPrefix, marker, value)
+			return nil
+		}
+		return &f
+	}
+	integer := func(marker string) *int64 {
+		if f := number(marker); f != nil {
+			i := int64(*f)
+			return &i
+		}
+		return nil
+	}
+	flag := func(marker string) bool {
+		value, _ := v.Get(marker)
+		return value == "true"
+	}
+
+	if value, ok := v.Get("Type"); ok {
+		s.Type = value
+	}
+	if value, ok := v.Get("Format"); ok {
+		s.Format = value
+	}
+	s.Minimum, s.Maximum = number("Minimum"), number("Maximum")
+	if b.openAPI {
+		if flag("ExclusiveMinimum") {
+			s.ExclusiveMinimum = true
+		}
+		if flag("ExclusiveMaximum") {
+			s.ExclusiveMaximum = true
+		}
+	} else {
+		// the markers follow OpenAPI v3.0, where the exclusive bounds are
+		// booleans qualifying the minimum and maximum
+		if flag("ExclusiveMinimum") && s.Minimum != nil {
+			s.ExclusiveMinimum, s.Minimum = *s.Minimum, nil
+		}
+		if flag("ExclusiveMaximum") && s.Maximum != nil {
+			s.ExclusiveMaximum, s.Maximum = *s.Maximum, nil
+		}
+	}
+	s.MultipleOf = number("MultipleOf")
+	s.MinLength, s.MaxLength = integer("MinLength"), integer("MaxLength")
+	if value, ok := v.Get("Pattern"); ok {
+		s.Pattern = value
+	}
+	s.MinItems, s.MaxItems = integer("MinItems"), integer("MaxItems")
+	s.UniqueItems = flag("UniqueItems")
+	s.MinProperties, s.MaxProperties = integer("MinProperties"), integer("MaxProperties")
+}
+
+// kindSchema returns the JSON Schema of manifests of the Kind t, with the
+// definitions of the local types it uses.
+func (r *renderer) kindSchema(t *types.Type) *jsonSchema {
+	b := newSchemaBuilder(r, "#/definitions/")
+	s := b.structSchema(t)
+	s.Schema = jsonSchemaDraft
+	s.Title = t.Name.Name
+	addTypeMeta(s, t, r.typePkgMap[t])
+	if len(b.definitions) > 0 {
+		s.Definitions = b.definitions
+	}
+	return s
+}
+
+// addTypeMeta sets the apiVersion and kind properties of the schema s of
+// the Kind t. TypeMeta is commonly hidden from the docs, but it is required
+// in manifests.
+func addTypeMeta(s *jsonSchema, t *types.Type, pkg *apiPackage) {
+	s.Properties["apiVersion"] = &jsonSchema{
+		Type:        "string",
+		Description: "APIVersion defines the versioned schema of this representation of an object.",
+		Enum:        []interface{}{pkg.groupVersion()},
+	}
+	s.Properties["kind"] = &jsonSchema{
+		Type:        "string",
+		Description: "Kind is a string value representing the REST resource this object represents.",
+		Enum:        []interface{}{t.Name.Name},
+	}
+	required := []string{"apiVersion", "kind"}
+	for _, name := range s.Required {
+		if name != "apiVersion" && name != "kind" {
+			required = append(required, name)
+		}
+	}
+	s.Required = required
+}
+
+// jsonSchemas renders the JSON Schema of every visible Kind, by path.
+func (r *renderer) jsonSchemas() (map[string]string, error) {
+	out := make(map[string]string)
+	for _, pkg := range r.pkgs {
+		for _, t := range visibleTypes(sortTypes(pkg.Types), r.config) {
+			if !isExportedType(t) || isListType(t) {
+				continue
+			}
+			b, err := json.MarshalIndent(r.kindSchema(t), "", "  ")
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to marshal the schema of %s", t.Name)
+			}
+			p := path.Join(pkg.apiGroup, pkg.apiVersion, strings.ToLower(t.Name.Name)+".json")
+			out[p] = string(b) + "\n"
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/types"
+)
+
+func TestIsRequiredMember(t *testing.T) {
+	tests := []struct {
+		name   string
+		member types.Member
+		want   bool
+	}{
+		{"unmarked", testMember("Size", types.Int32, `json:"size"`), true},
+		{"omitempty", testMember("Size", types.Int32, `json:"size,omitempty"`), false},
+		{"optional", testMember("Size", types.Int32, `json:"size"`, "+optional"), false},
+		{"required omitempty", testMember("Size", types.Int32, `json:"size,omitempty"`, "+required"), true},
+		{"validation optional", testMember("Size", types.Int32, `json:"size"`, "+kubebuilder:validation:Optional"), false},
+		{"validation required over optional", testMember("Size", types.Int32, `json:"size"`, "+optional", "+kubebuilder:validation:Required"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRequiredMember(tt.member); got != tt.want {
+				t.Errorf("isRequiredMember() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsOptionalMemberOnlyHonorsOptionalMarker(t *testing.T) {
+	// the docs show "(Optional)" for +optional fields only
+	for _, m := range []types.Member{
+		testMember("Size", types.Int32, `json:"size,omitempty"`),
+		testMember("Size", types.Int32, `json:"size"`, "+kubebuilder:validation:Optional"),
+	} {
+		if isOptionalMember(m) {
+			t.Errorf("isOptionalMember(%s %s) = true, want false", m.Tags, m.CommentLines)
+		}
+	}
+	if !isOptionalMember(testMember("Size", types.Int32, `json:"size"`, "+optional")) {
+		t.Errorf("isOptionalMember(+optional) = false, want true")
+	}
+}
+
+func TestKindSchemaAPIVersion(t *testing.T) {
+	tests := []struct {
+		group string
+		want  string
+	}{
+		{"widgets.example.com", "widgets.example.com/v1"},
+		{"", "v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			kind := testKind("Widget")
+			pkg := &apiPackage{apiGroup: tt.group, apiVersion: "v1", Types: []*types.Type{kind}}
+			r := newRenderer([]*apiPackage{pkg}, GeneratorConfig{}, formatMarkdown)
+			got := r.kindSchema(kind).Properties["apiVersion"].Enum
+			if want := []interface{}{tt.want}; !reflect.DeepEqual(got, want) {
+				t.Errorf("apiVersion enum = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestTypeSchema(t *testing.T) {
+	builtin := func(name string) *types.Type {
+		return &types.Type{Name: types.Name{Name: name}, Kind: types.Builtin}
+	}
+	external := func(pkg, name string) *types.Type {
+		return &types.Type{Name: types.Name{Package: pkg, Name: name}, Kind: types.Struct}
+	}
+	tests := []struct {
+		t    *types.Type
+		want *jsonSchema
+	}{
+		{types.Int32, &jsonSchema{Type: "integer", Format: "int32"}},
+		{builtin("int16"), &jsonSchema{Type: "integer", Format: "int32"}},
+		{builtin("uint8"), &jsonSchema{Type: "integer", Format: "int32"}},
+		{types.Int, &jsonSchema{Type: "integer", Format: "int64"}},
+		{types.Uint, &jsonSchema{Type: "integer", Format: "int64"}},
+		{builtin("uint32"), &jsonSchema{Type: "integer", Format: "int64"}},
+		{types.Int64, &jsonSchema{Type: "integer", Format: "int64"}},
+		{types.Float64, &jsonSchema{Type: "number", Format: "double"}},
+		{&types.Type{Kind: types.Slice, Elem: types.Byte}, &jsonSchema{Type: "string", Format: "byte"}},
+		{
+			external("k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1", "JSON"),
+			&jsonSchema{XPreserveUnknownFields: true},
+		},
+		{
+			external("k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1", "JSON"),
+			&jsonSchema{XPreserveUnknownFields: true},
+		},
+	}
+	b := newSchemaBuilder(newRenderer(nil, GeneratorConfig{}, formatJSONSchema), "#/definitions/")
+	for _, tt := range tests {
+		t.Run(typeIdentifier(tt.t), func(t *testing.T) {
+			if got := b.typeSchema(tt.t); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("typeSchema() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefinitionName(t *testing.T) {
+	tests := []struct {
+		name types.Name
+		want string
+	}{
+		{types.Name{Package: "example.com/api/widgets/v1", Name: "Widget"}, "example.com_api_widgets_v1.Widget"},
+		{types.Name{Package: "example.com/api/v1beta1", Name: "WidgetSpec"}, "example.com_api_v1beta1.WidgetSpec"},
+		{types.Name{Package: "example.com/~user/api/v1", Name: "Widget"}, "example.com__user_api_v1.Widget"},
+	}
+	for _, tt := range tests {
+		if got := definitionName(&types.Type{Name: tt.name}); got != tt.want {
+			t.Errorf("definitionName(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+)
+
+const (
+	// watchInterval is how often the live server polls its inputs for changes.
+	watchInterval = time.Second
+
+	// reloadPath is the server-sent events endpoint the served page listens
+	// on to know when to reload itself.
+	reloadPath = "/_reload"
+
+	reloadScript = `<script>new EventSource("` + reloadPath + `").onmessage = function() { location.reload(); };</script>`
+)
+
+// liveServer holds the result of the latest build and notifies connected
+// browser tabs whenever a new one is available.
+type liveServer struct {
+	mu      sync.RWMutex
+	doc     string
+	err     error
+	clients map[chan struct{}]struct{}
+}
+
+// serveWithLiveReload starts the HTTP server on -http-addr, rebuilding the
+// document whenever the API sources, the templates, the config file or the
+// examples change.
+func serveWithLiveReload() {
+	s := &liveServer{clients: make(map[chan struct{}]struct{})}
+	s.rebuild()
+	go s.watch()
+
+	http.HandleFunc("/", s.handlePage)
+	http.HandleFunc(reloadPath, s.handleReload)
+	klog.Infof("server listening at %s", *flHTTPAddr)
+	klog.Fatal(http.ListenAndServe(*flHTTPAddr, nil))
+}
+
+// rebuild regenerates the document from scratch, including re-reading the
+// config file, and tells all open pages to reload.
+func (s *liveServer) rebuild() {
+	now := time.Now()
+	doc, err := func() (string, error) {
+		config, err := loadConfig(*flConfig)
+		if err != nil {
+			return "", err
+		}
+		return buildDoc(config)
+	}()
+	if err != nil {
+		klog.Warningf("rebuild failed: %+v", err)
+	} else {
+		klog.Infof("rebuild took %v", time.Since(now))
+	}
+
+	s.mu.Lock()
+	s.doc, s.err = doc, err
+	for c := range s.clients {
+		select {
+		case c <- struct{}{}:
+		default: // a reload is already pending for this client
+		}
+	}
+	s.mu.Unlock()
+}
+
+// watch polls the watched inputs and triggers a rebuild when any of them
+// is added, removed or modified. It never returns.
+func (s *liveServer) watch() {
+	last := snapshotInputs()
+	for range time.Tick(watchInterval) {
+		cur := snapshotInputs()
+		if cur != last {
+			klog.Infof("change detected, rebuilding")
+			s.rebuild()
+			last = cur
+		}
+	}
+}
+
+func (s *liveServer) handlePage(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	defer func() { klog.Infof("request took %v", time.Since(now)) }()
+
+	s.mu.RLock()
+	doc, err := s.doc, s.err
+	s.mu.RUnlock()
+
+	if err != nil {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		doc = fmt.Sprintf("<!doctype html>\n<html><body><h1>Build failed</h1><pre>%s</pre></body></html>",
+			html.EscapeString(fmt.Sprintf("%+v", err)))
+	}
+	if strings.HasPrefix(http.DetectContentType([]byte(doc)), "text/html") {
+		doc = injectReloadScript(doc)
+	}
+
+	if _, err := fmt.Fprint(w, doc); err != nil {
+		klog.Warningf("response write error: %v", err)
+	}
+}
+
+// handleReload streams a server-sent event every time a rebuild completes.
+func (s *liveServer) handleReload(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher.Flush()
+
+	c := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.clients[c] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, c)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-c:
+			if _, err := fmt.Fprint(w, "data: reload\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// injectReloadScript adds the live reload client right before </body>, or at
+// the end of the document if it has none.
+func injectReloadScript(doc string) string {
+	if i := strings.LastIndex(doc, "</body>"); i >= 0 {
+		return doc[:i] + reloadScript + doc[i:]
+	}
+	return doc + reloadScript
+}
+
+// snapshotInputs fingerprints the Go sources under -api-dir, the templates in
+// the -template-dir directories, the config file and the examples of its
+// examplesDir. Two snapshots differ if any file was added, removed or
+// modified in between.
+func snapshotInputs() string {
+	var b strings.Builder
+	add := func(path string, fi os.FileInfo) {
+		fmt.Fprintf(&b, "%s\x00%d\x00%d\n", path, fi.Size(), fi.ModTime().UnixNano())
+	}
+	walk := func(root, ext string) {
+		err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return nil // files may disappear while we walk, skip them
+			}
+			if fi.IsDir() && fi.Name() == "vendor" {
+				return filepath.SkipDir
+			}
+			if !fi.IsDir() && filepath.Ext(path) == ext {
+				add(path, fi)
+			}
+			return nil
+		})
+		if err != nil {
+			klog.Warningf("failed to watch %s: %v", root, err)
+		}
+	}
+
+	walk(*flAPIDir, ".go")
+	for _, dir := range *flTemplateDirs {
+		walk(dir, ".tpl")
+	}
+	if fi, err := os.Stat(*flConfig); err == nil {
+		add(*flConfig, fi)
+	}
+	if config, err := loadConfig(*flConfig); err == nil && config.ExamplesDir != "" {
+		walk(config.ExamplesDir, ".yaml")
+		walk(config.ExamplesDir, ".yml")
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// setFlag sets the flag at p to value for the duration of the test.
+func setFlag(t *testing.T, p *string, value string) {
+	old := *p
+	t.Cleanup(func() { *p = old })
+	*p = value
+}
+
+// writeTestFile writes content to path, creating its directory.
+func writeTestFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInjectReloadScript(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"before the body end", "<html><body>x</body></html>", "<html><body>x" + reloadScript + "</body></html>"},
+		{"before the last body end", "<body>a</body><body>b</body>", "<body>a</body><body>b" + reloadScript + "</body>"},
+		{"without a body end", "<p>x</p>", "<p>x</p>" + reloadScript},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := injectReloadScript(tt.doc); got != tt.want {
+				t.Errorf("injectReloadScript() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotInputs(t *testing.T) {
+	dir := t.TempDir()
+	apiDir := filepath.Join(dir, "api")
+	templateDir := filepath.Join(dir, "templates")
+	config := filepath.Join(dir, "config.json")
+	writeTestFile(t, filepath.Join(apiDir, "v1", "types.go"), "package v1\n")
+	writeTestFile(t, filepath.Join(templateDir, "page.tpl"), "{{ . }}")
+	writeTestFile(t, config, "{}")
+	setFlag(t, flAPIDir, apiDir)
+	setTemplateDirs(t, templateDir)
+	setFlag(t, flConfig, config)
+
+	tests := []struct {
+		name    string
+		change  func()
+		changed bool
+	}{
+		{"nothing", func() {}, false},
+		{"source modified", func() {
+			writeTestFile(t, filepath.Join(apiDir, "v1", "types.go"), "package v1\n\ntype Widget struct{}\n")
+		}, true},
+		{"source added", func() { writeTestFile(t, filepath.Join(apiDir, "v2", "types.go"), "package v2\n") }, true},
+		{"source removed", func() { os.RemoveAll(filepath.Join(apiDir, "v2")) }, true},
+		{"template modified", func() { writeTestFile(t, filepath.Join(templateDir, "page.tpl"), "{{ .Name }}") }, true},
+		{"config modified", func() { writeTestFile(t, config, `{"hideMemberFields": ["TypeMeta"]}`) }, true},
+		{"other file added", func() { writeTestFile(t, filepath.Join(apiDir, "v1", "README.md"), "v1") }, false},
+		{"vendored source added", func() { writeTestFile(t, filepath.Join(apiDir, "vendor", "dep", "dep.go"), "package dep\n") }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := snapshotInputs()
+			tt.change()
+			if changed := snapshotInputs() != before; changed != tt.changed {
+				t.Errorf("snapshots differ: %v, want %v", changed, tt.changed)
+			}
+		})
+	}
+}
+
+func TestHandlePageAfterFailedBuild(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.json")
+	writeTestFile(t, config, "{")
+	setFlag(t, flConfig, config)
+
+	s := &liveServer{clients: make(map[chan struct{}]struct{})}
+	c := make(chan struct{}, 1)
+	s.clients[c] = struct{}{}
+	s.rebuild()
+	select {
+	case <-c:
+	default:
+		t.Errorf("the client was not told to reload after the failed build")
+	}
+
+	w := httptest.NewRecorder()
+	s.handlePage(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"<h1>Build failed</h1>", "failed to parse config file", reloadScript + "</body>"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page does not contain %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestHandlePageEscapesTheError(t *testing.T) {
+	s := &liveServer{err: errors.New("<script>alert(1)</script>")}
+	w := httptest.NewRecorder()
+	s.handlePage(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if body := w.Body.String(); strings.Contains(body, "<script>alert") {
+		t.Errorf("page does not escape the error:\n%s", body)
+	}
+}
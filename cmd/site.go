@@ -0,0 +1,183 @@
+package main
+
+import (
+	"path"
+	"strings"
+
+	"k8s.io/gengo/types"
+)
+
+const (
+	// pageSingle is the whole documentation on one page, written to
+	// -out-file or served on -http-addr.
+	pageSingle = "single"
+	// pageIndex is the entry page of the -out-dir output.
+	pageIndex = "index"
+	// pagePackage documents an API group version.
+	pagePackage = "package"
+	// pageKind documents a single Kind, with -kind-pages.
+	pageKind = "kind"
+)
+
+// fileExtensions are the extensions of the pages of each output format.
+var fileExtensions = map[string]string{
+	formatHTML:     ".html",
+	formatMarkdown: ".md",
+	formatAsciiDoc: ".adoc",
+}
+
+// page is a page of the output, passed to the templates as ".page".
+type page struct {
+	// Kind is one of pageSingle, pageIndex, pagePackage or pageKind.
+	Kind string
+	// Path is the location of the page relative to -out-dir, with forward
+	// slashes. It is empty for single-page output.
+	Path  string
+	Title string
+	// Description summarizes the page, for the site generator front matter.
+	Description string
+	// Packages are the API packages whose types are documented on the page.
+	Packages []*apiPackage
+	// Type is the Kind documented on a pageKind page.
+	Type *types.Type
+}
+
+// siteLayout decides which page of the -out-dir output every package and
+// type is documented on. Packages get a page at "<group>/<version>/index",
+// and so do Kinds if kindPages is set, at "<group>/<version>/<kind>".
+type siteLayout struct {
+	ext        string
+	kindPages  bool
+	config     GeneratorConfig
+	typePkgMap map[*types.Type]*apiPackage
+}
+
+func newSiteLayout(format string, kindPages bool, config GeneratorConfig, typePkgMap map[*types.Type]*apiPackage) *siteLayout {
+	return &siteLayout{
+		ext:        fileExtensions[format],
+		kindPages:  kindPages,
+		config:     config,
+		typePkgMap: typePkgMap,
+	}
+}
+
+// pages lists every page of the site, the index first, then the packages
+// each followed by its Kinds.
+func (l *siteLayout) pages(pkgs []*apiPackage) []*page {
+	index := &page{Kind: pageIndex, Path: l.indexPage(), Title: "API Reference"}
+	if sg := l.config.SiteGenerator; sg != nil {
+		index.Title, index.Description = sg.title(), sg.Description
+	}
+	out := []*page{index}
+	for _, pkg := range pkgs {
+		p := &page{
+			Kind:     pagePackage,
+			Path:     l.packagePage(pkg),
+			Title:    pkg.identifier(),
+			Packages: []*apiPackage{pkg},
+		}
+		if len(pkg.GoPackages) > 0 {
+			p.Description = commentSummary(pkg.GoPackages[0].DocComments)
+		}
+		out = append(out, p)
+		for _, t := range visibleTypes(sortTypes(pkg.Types), l.config) {
+			if l.hasKindPage(t) {
+				out = append(out, &page{
+					Kind:        pageKind,
+					Path:        l.typePage(t),
+					Title:       t.Name.Name,
+					Description: commentSummary(t.CommentLines),
+					Type:        t,
+				})
+			}
+		}
+	}
+	return out
+}
+
+func (l *siteLayout) indexPage() string { return l.config.SiteGenerator.indexName() + l.ext }
+
+func (l *siteLayout) packageDir(pkg *apiPackage) string {
+	return path.Join(pkg.apiGroup, pkg.apiVersion)
+}
+
+func (l *siteLayout) packagePage(pkg *apiPackage) string {
+	return path.Join(l.packageDir(pkg), l.config.SiteGenerator.indexName()+l.ext)
+}
+
+// hasKindPage reports whether the local type t is documented on a page of
+// its own: the Kinds, but not their list types, if kindPages is set.
+func (l *siteLayout) hasKindPage(t *types.Type) bool {
+	return l.kindPages && isExportedType(t) && !isListType(t)
+}
+
+// typePage returns the page the local type t is documented on.
+func (l *siteLayout) typePage(t *types.Type) string {
+	pkg := l.typePkgMap[t]
+	if l.hasKindPage(t) {
+		return path.Join(l.packageDir(pkg), strings.ToLower(t.Name.Name)+l.ext)
+	}
+	return l.packagePage(pkg)
+}
+
+// pageLinker produces the links of the page being rendered. A nil
+// *pageLinker is valid and links to anchors of a single page.
+type pageLinker struct {
+	layout  *siteLayout
+	current *page
+}
+
+// link returns a link to anchor on the page at target, relative to the
+// current page. The link is to the page itself if anchor is empty.
+func (pl *pageLinker) link(target, anchor string) string {
+	if pl == nil || target == pl.current.Path {
+		return "#" + anchor
+	}
+	return pl.layout.config.SiteGenerator.link(relativePath(path.Dir(pl.current.Path), target), anchor)
+}
+
+// relativePath returns the path of target relative to the directory dir,
+// both relative to -out-dir with forward slashes.
+func relativePath(dir, target string) string {
+	if dir == "." {
+		return target
+	}
+	from, to := strings.Split(dir, "/"), strings.Split(target, "/")
+	i := 0
+	for i < len(from) && i < len(to)-1 && from[i] == to[i] {
+		i++
+	}
+	return strings.Repeat("../", len(from)-i) + strings.Join(to[i:], "/")
+}
+
+// typeLink returns a link to the local type t, whose anchor is anchor.
+func (pl *pageLinker) typeLink(t *types.Type, anchor string) string {
+	if pl == nil {
+		return "#" + anchor
+	}
+	return pl.link(pl.layout.typePage(t), anchor)
+}
+
+// packageLink returns a link to the section of pkg, whose anchor is anchor.
+func (pl *pageLinker) packageLink(pkg *apiPackage, anchor string) string {
+	if pl == nil {
+		return "#" + anchor
+	}
+	return pl.link(pl.layout.packagePage(pkg), anchor)
+}
+
+// pageTypes returns the visible types of pkg that are documented on the
+// current page.
+func (pl *pageLinker) pageTypes(pkg *apiPackage, c GeneratorConfig) []*types.Type {
+	all := visibleTypes(sortTypes(pkg.Types), c)
+	if pl == nil {
+		return all
+	}
+	var out []*types.Type
+	for _, t := range all {
+		if pl.layout.typePage(t) == pl.current.Path {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/types"
+)
+
+func TestRelativePath(t *testing.T) {
+	tests := []struct {
+		dir, target string
+		want        string
+	}{
+		{".", "widgets.example.com/v1/index.md", "widgets.example.com/v1/index.md"},
+		{"widgets.example.com/v1", "index.md", "../../index.md"},
+		{"widgets.example.com/v1", "widgets.example.com/v1/widget.md", "widget.md"},
+		{"widgets.example.com/v1", "widgets.example.com/v2/index.md", "../v2/index.md"},
+		{"widgets.example.com/v1", "gadgets.example.com/v1/index.md", "../../gadgets.example.com/v1/index.md"},
+		{"v1", "v1/widget.md", "widget.md"},
+	}
+	for _, tt := range tests {
+		if got := relativePath(tt.dir, tt.target); got != tt.want {
+			t.Errorf("relativePath(%q, %q) = %q, want %q", tt.dir, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestSiteLayoutPages(t *testing.T) {
+	widget := testKind("Widget")
+	list := testKind("WidgetList")
+	list.Members = []types.Member{testMember("Items", &types.Type{Kind: types.Slice, Elem: widget}, `json:"items"`)}
+	spec := testStruct()
+	pkg := &apiPackage{apiGroup: "widgets.example.com", apiVersion: "v1", Types: []*types.Type{widget, list, spec}}
+	typePkgMap := extractTypeToPackageMap([]*apiPackage{pkg})
+
+	tests := []struct {
+		name      string
+		kindPages bool
+		want      []string
+	}{
+		{"package pages", false, []string{"index.md", "widgets.example.com/v1/index.md"}},
+		{"kind pages", true, []string{"index.md", "widgets.example.com/v1/index.md", "widgets.example.com/v1/widget.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newSiteLayout(formatMarkdown, tt.kindPages, GeneratorConfig{}, typePkgMap)
+			var got []string
+			for _, p := range l.pages([]*apiPackage{pkg}) {
+				got = append(got, p.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pages() = %v, want %v", got, tt.want)
+			}
+			if p := l.typePage(list); p != "widgets.example.com/v1/index.md" {
+				t.Errorf("typePage(WidgetList) = %q, want the package page", p)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	siteGeneratorHugo       = "hugo"
+	siteGeneratorDocusaurus = "docusaurus"
+)
+
+var siteGenerators = []string{siteGeneratorHugo, siteGeneratorDocusaurus}
+
+// siteGeneratorConfig integrates the -out-dir Markdown output with a static
+// site generator.
+type siteGeneratorConfig struct {
+	// Name is the site generator, "hugo" or "docusaurus".
+	Name string `json:"name"`
+
+	// Title is the title of the index page, "API Reference" by default.
+	Title string `json:"title"`
+
+	// Description is the description of the index page.
+	Description string `json:"description"`
+
+	// Weight orders the index page among its siblings. The pages below it
+	// are ordered after it, by API group, version and Kind.
+	Weight int `json:"weight"`
+
+	// FrontMatter holds additional front matter fields set on every page.
+	FrontMatter map[string]interface{} `json:"frontMatter"`
+
+	// DocIDPrefix is the path of -out-dir in the Docusaurus docs directory,
+	// used to reference the pages in sidebars.js.
+	DocIDPrefix string `json:"docIDPrefix"`
+
+	// SidebarID is the name of the sidebar in sidebars.js, "apiReference" by
+	// default.
+	SidebarID string `json:"sidebarID"`
+
+	// tag is the git tag of the versioned docs being generated, if any.
+	tag string
+}
+
+func (c *siteGeneratorConfig) validate() error {
+	if !containsString(siteGenerators, c.Name) {
+		return errors.Errorf("unknown siteGenerator name %q, must be one of %v", c.Name, siteGenerators)
+	}
+	return nil
+}
+
+func (c *siteGeneratorConfig) title() string {
+	if c.Title == "" {
+		return "API Reference"
+	}
+	return c.Title
+}
+
+// indexName is the name of the pages that are the index of their
+// directory, which Hugo requires to be "_index" to create a section.
+func (c *siteGeneratorConfig) indexName() string {
+	if c != nil && c.Name == siteGeneratorHugo {
+		return "_index"
+	}
+	return "index"
+}
+
+// link formats a link to another page of the site, given its path relative
+// to the current page and an optional anchor. Hugo resolves page links with
+// the relref shortcode, Docusaurus resolves relative links to Markdown files.
+func (c *siteGeneratorConfig) link(rel, anchor string) string {
+	if anchor != "" {
+		rel += "#" + anchor
+	}
+	if c != nil && c.Name == siteGeneratorHugo {
+		return fmt.Sprintf(`{{< relref %q >}}`, rel)
+	}
+	return rel
+}
+
+// frontMatter returns the YAML front matter of a page.
+func (c *siteGeneratorConfig) frontMatter(p *page, weight int) (string, error) {
+	fm := make(map[string]interface{}, len(c.FrontMatter)+3)
+	for k, v := range c.FrontMatter {
+		fm[k] = v
+	}
+	fm["title"] = p.Title
+	if p.Description != "" {
+		fm["description"] = p.Description
+	}
+	if c.Name == siteGeneratorDocusaurus {
+		fm["sidebar_position"] = weight
+	} else {
+		fm["weight"] = weight
+	}
+	b, err := yaml.Marshal(fm)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal the front matter of %s", p.Path)
+	}
+	return "---\n" + string(b) + "---\n\n", nil
+}
+
+// decorate adds the front matter to the rendered pages, in the order of
+// pages, and the navigation files the site generator needs.
+func (c *siteGeneratorConfig) decorate(out map[string]string, pages []*page) error {
+	weights := make(map[string]int)
+	for i, p := range pages {
+		weights[p.Path] = c.Weight + i
+		fm, err := c.frontMatter(p, weights[p.Path])
+		if err != nil {
+			return err
+		}
+		out[p.Path] = fm + out[p.Path]
+	}
+
+	// directories of API groups have no page of their own
+	groups := make(map[string]int)
+	for _, p := range pages {
+		if p.Kind == pagePackage {
+			group := p.Packages[0].apiGroup
+			if _, ok := groups[group]; !ok {
+				groups[group] = weights[p.Path]
+			}
+		}
+	}
+
+	switch c.Name {
+	case siteGeneratorHugo:
+		for group, weight := range groups {
+			fm, err := c.frontMatter(&page{Path: group, Title: group}, weight)
+			if err != nil {
+				return err
+			}
+			out[path.Join(group, "_index.md")] = fm
+		}
+	case siteGeneratorDocusaurus:
+		for group, weight := range groups {
+			category, err := json.MarshalIndent(map[string]interface{}{"label": group, "position": weight}, "", "  ")
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal _category_.json")
+			}
+			out[path.Join(group, "_category_.json")] = string(category) + "\n"
+		}
+		if c.tag != "" {
+			// the sidebar of versioned docs is written with the tag index
+			break
+		}
+		sidebars, err := c.sidebars(pages)
+		if err != nil {
+			return err
+		}
+		out["sidebars.js"] = sidebars
+	}
+	return nil
+}
+
+// forTag returns the config of the documentation of the git tag, the
+// position-th of the versioned docs, whose index page is titled after the
+// tag and ordered after the tag index.
+func (c *siteGeneratorConfig) forTag(tag string, position int) *siteGeneratorConfig {
+	tc := *c
+	tc.Title = c.title() + " " + tag
+	tc.Weight = c.Weight + 1 + position
+	tc.tag = tag
+	return &tc
+}
+
+// decorateTagIndex adds the front matter to the index page of the versioned
+// docs, and for Docusaurus the sidebar listing the docs of every tag.
+func (c *siteGeneratorConfig) decorateTagIndex(out map[string]string, index string, docs []*taggedDocs) error {
+	fm, err := c.frontMatter(&page{Path: index, Title: c.title(), Description: c.Description}, c.Weight)
+	if err != nil {
+		return err
+	}
+	out[index] = fm + out[index]
+	if c.Name != siteGeneratorDocusaurus {
+		return nil
+	}
+	items := []*sidebarItem{{Type: "doc", ID: path.Join(c.DocIDPrefix, strings.TrimSuffix(index, path.Ext(index))), Label: c.title()}}
+	for _, d := range docs {
+		items = append(items, &sidebarItem{
+			Type:  "category",
+			Label: d.Tag,
+			Items: []*sidebarItem{{Type: "autogenerated", DirName: path.Join(c.DocIDPrefix, d.Tag)}},
+		})
+	}
+	sidebars, err := c.sidebarsFile(items)
+	if err != nil {
+		return err
+	}
+	out["sidebars.js"] = sidebars
+	return nil
+}
+
+// sidebarItem is an entry of a Docusaurus sidebar.
+type sidebarItem struct {
+	Type  string `json:"type"`
+	ID    string `json:"id,omitempty"`
+	Label string `json:"label,omitempty"`
+	// DirName is the directory of an "autogenerated" item.
+	DirName string         `json:"dirName,omitempty"`
+	Link    *sidebarItem   `json:"link,omitempty"`
+	Items   []*sidebarItem `json:"items,omitempty"`
+}
+
+// sidebars returns a Docusaurus sidebars.js file with a sidebar listing the
+// pages by API group and version.
+func (c *siteGeneratorConfig) sidebars(pages []*page) (string, error) {
+	docID := func(p *page) string {
+		return path.Join(c.DocIDPrefix, strings.TrimSuffix(p.Path, path.Ext(p.Path)))
+	}
+
+	items := []*sidebarItem{{Type: "doc", ID: docID(pages[0]), Label: pages[0].Title}}
+	groups := make(map[string]*sidebarItem)
+	versions := make(map[string]*sidebarItem)
+	for _, p := range pages[1:] {
+		switch p.Kind {
+		case pagePackage:
+			pkg := p.Packages[0]
+			group, ok := groups[pkg.apiGroup]
+			if !ok {
+				group = &sidebarItem{Type: "category", Label: pkg.apiGroup}
+				groups[pkg.apiGroup] = group
+				items = append(items, group)
+			}
+			version := &sidebarItem{
+				Type:  "category",
+				Label: pkg.apiVersion,
+				Link:  &sidebarItem{Type: "doc", ID: docID(p)},
+			}
+			versions[path.Dir(p.Path)] = version
+			group.Items = append(group.Items, version)
+		case pageKind:
+			version := versions[path.Dir(p.Path)]
+			version.Items = append(version.Items, &sidebarItem{Type: "doc", ID: docID(p), Label: p.Title})
+		}
+	}
+
+	for _, version := range versions {
+		if len(version.Items) == 0 {
+			// categories must have items, so without Kind pages each
+			// version is a plain link to its page
+			*version = sidebarItem{Type: "doc", ID: version.Link.ID, Label: version.Label}
+		}
+	}
+	return c.sidebarsFile(items)
+}
+
+// sidebarsFile returns a Docusaurus sidebars.js file with the sidebar items.
+func (c *siteGeneratorConfig) sidebarsFile(items []*sidebarItem) (string, error) {
+	sidebarID := c.SidebarID
+	if sidebarID == "" {
+		sidebarID = "apiReference"
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(map[string][]*sidebarItem{sidebarID: items}); err != nil {
+		return "", errors.Wrap(err, "failed to marshal sidebars.js")
+	}
+	return "// Generated documentation. Please do not edit.\nmodule.exports = " +
+		strings.TrimSpace(b.String()) + ";\n", nil
+}
+
+// commentSummary returns the first paragraph of a doc comment, on one line.
+func commentSummary(lines []string) string {
+	var out []string
+	for _, l := range filterCommentTags(lines) {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			if len(out) > 0 {
+				break
+			}
+			continue
+		}
+		out = append(out, l)
+	}
+	return strings.Join(out, " ")
+}
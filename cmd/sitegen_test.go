@@ -0,0 +1,175 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"k8s.io/gengo/types"
+)
+
+// testSite returns the pages of a site with a Kind page, and their rendered
+// content, which is the page path.
+func testSite(sg *siteGeneratorConfig) ([]*page, map[string]string) {
+	widget := testKind("Widget")
+	widget.CommentLines = []string{"Widget is a widget.", "", "It has a size."}
+	pkg := &apiPackage{apiGroup: "widgets.example.com", apiVersion: "v1", Types: []*types.Type{widget}}
+	l := newSiteLayout(formatMarkdown, true, GeneratorConfig{SiteGenerator: sg}, extractTypeToPackageMap([]*apiPackage{pkg}))
+	pages := l.pages([]*apiPackage{pkg})
+	out := make(map[string]string)
+	for _, p := range pages {
+		out[p.Path] = p.Path
+	}
+	return pages, out
+}
+
+func TestSiteGeneratorLink(t *testing.T) {
+	tests := []struct {
+		name   string
+		sg     *siteGeneratorConfig
+		target string
+		want   string
+	}{
+		{"no site generator", nil, "widgets.example.com/v1/index.md", "index.md#widgetspec"},
+		{"hugo", &siteGeneratorConfig{Name: siteGeneratorHugo}, "widgets.example.com/v1/_index.md", `{{< relref "_index.md#widgetspec" >}}`},
+		{"hugo index", &siteGeneratorConfig{Name: siteGeneratorHugo}, "_index.md", `{{< relref "../../_index.md#widgetspec" >}}`},
+		{"docusaurus", &siteGeneratorConfig{Name: siteGeneratorDocusaurus}, "widgets.example.com/v1/index.md", "index.md#widgetspec"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl := &pageLinker{
+				layout:  newSiteLayout(formatMarkdown, true, GeneratorConfig{SiteGenerator: tt.sg}, nil),
+				current: &page{Path: "widgets.example.com/v1/widget.md"},
+			}
+			if got := pl.link(tt.target, "widgetspec"); got != tt.want {
+				t.Errorf("link() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecorateHugo(t *testing.T) {
+	sg := &siteGeneratorConfig{
+		Name:        siteGeneratorHugo,
+		Description: "The widgets API.",
+		Weight:      10,
+		FrontMatter: map[string]interface{}{"draft": false},
+	}
+	pages, out := testSite(sg)
+	if err := sg.decorate(out, pages); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"_index.md":                     "---\ndescription: The widgets API.\ndraft: false\ntitle: API Reference\nweight: 10\n---\n\n_index.md",
+		"widgets.example.com/_index.md": "---\ndraft: false\ntitle: widgets.example.com\nweight: 11\n---\n\n",
+		"widgets.example.com/v1/_index.md": "---\ndraft: false\ntitle: widgets.example.com/v1\nweight: 11\n---\n\n" +
+			"widgets.example.com/v1/_index.md",
+		"widgets.example.com/v1/widget.md": "---\ndescription: Widget is a widget.\ndraft: false\ntitle: Widget\nweight: 12\n---\n\n" +
+			"widgets.example.com/v1/widget.md",
+	}
+	if !reflect.DeepEqual(out, want) {
+		for _, p := range sortedKeys(out) {
+			t.Errorf("%s:\n%s", p, out[p])
+		}
+	}
+}
+
+func TestDecorateDocusaurus(t *testing.T) {
+	sg := &siteGeneratorConfig{Name: siteGeneratorDocusaurus, DocIDPrefix: "reference"}
+	pages, out := testSite(sg)
+	if err := sg.decorate(out, pages); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"index.md": "---\nsidebar_position: 0\ntitle: API Reference\n---\n\nindex.md",
+		"widgets.example.com/v1/index.md": "---\nsidebar_position: 1\ntitle: widgets.example.com/v1\n---\n\n" +
+			"widgets.example.com/v1/index.md",
+		"widgets.example.com/v1/widget.md": "---\ndescription: Widget is a widget.\nsidebar_position: 2\ntitle: Widget\n---\n\n" +
+			"widgets.example.com/v1/widget.md",
+		"widgets.example.com/_category_.json": "{\n  \"label\": \"widgets.example.com\",\n  \"position\": 1\n}\n",
+		"sidebars.js": `// Generated documentation. Please do not edit.
+module.exports = {
+  "apiReference": [
+    {
+      "type": "doc",
+      "id": "reference/index",
+      "label": "API Reference"
+    },
+    {
+      "type": "category",
+      "label": "widgets.example.com",
+      "items": [
+        {
+          "type": "category",
+          "label": "v1",
+          "link": {
+            "type": "doc",
+            "id": "reference/widgets.example.com/v1/index"
+          },
+          "items": [
+            {
+              "type": "doc",
+              "id": "reference/widgets.example.com/v1/widget",
+              "label": "Widget"
+            }
+          ]
+        }
+      ]
+    }
+  ]
+};
+`,
+	}
+	if !reflect.DeepEqual(out, want) {
+		for _, p := range sortedKeys(out) {
+			t.Errorf("%s:\n%s", p, out[p])
+		}
+	}
+}
+
+func TestSidebarsWithoutKindPages(t *testing.T) {
+	sg := &siteGeneratorConfig{Name: siteGeneratorDocusaurus, SidebarID: "api"}
+	pages, _ := testSite(sg)
+	pages = pages[:2]
+	got, err := sg.sidebars(pages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `// Generated documentation. Please do not edit.
+module.exports = {
+  "api": [
+    {
+      "type": "doc",
+      "id": "index",
+      "label": "API Reference"
+    },
+    {
+      "type": "category",
+      "label": "widgets.example.com",
+      "items": [
+        {
+          "type": "doc",
+          "id": "widgets.example.com/v1/index",
+          "label": "v1"
+        }
+      ]
+    }
+  ]
+};
+`
+	if got != want {
+		t.Errorf("sidebars() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
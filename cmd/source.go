@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"k8s.io/gengo/types"
+	"k8s.io/klog"
+)
+
+// sourcePos is where a type or member is declared.
+type sourcePos struct {
+	// Path is the file, relative to the root of the git repository of the
+	// package, with forward slashes.
+	Path string `json:"path"`
+	Line int    `json:"line"`
+}
+
+// readSourcePositions finds where the types of pkg and their members are
+// declared, keyed by the type name and by the type name followed by "." and
+// the member name. gengo does not record positions, so the files are parsed
+// again. Nothing is returned for a package outside a git repository.
+func readSourcePositions(pkg *types.Package) (map[string]*sourcePos, error) {
+	dir, err := filepath.EvalSymlinks(pkg.SourcePath)
+	if err != nil {
+		return nil, err
+	}
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		klog.V(2).Infof("%s is not in a git repository, not recording source positions", pkg.Path)
+		return nil, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]*sourcePos)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := goparser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", file)
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return nil, err
+		}
+		pos := func(p token.Pos) *sourcePos {
+			return &sourcePos{Path: filepath.ToSlash(rel), Line: fset.Position(p).Line}
+		}
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				name := pkg.Path + "." + ts.Name.Name
+				out[name] = pos(ts.Name.Pos())
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				for _, field := range st.Fields.List {
+					for _, n := range field.Names {
+						out[name+"."+n.Name] = pos(n.Pos())
+					}
+					if len(field.Names) == 0 {
+						out[name+"."+embeddedFieldName(field.Type)] = pos(field.Pos())
+					}
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// embeddedFieldName returns the name of the field an embedded type expr
+// declares, which is the name of the type.
+func embeddedFieldName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedFieldName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+// typeSource returns where the local type t is declared, or nil if it is
+// not known.
+func (r *renderer) typeSource(t *types.Type) *sourcePos {
+	if pkg := r.typePkgMap[t]; pkg != nil {
+		return pkg.sources[t.Name.String()]
+	}
+	return nil
+}
+
+// memberSource returns where the member m of the local type t is declared,
+// or nil if it is not known.
+func (r *renderer) memberSource(t *types.Type, m types.Member) *sourcePos {
+	if pkg := r.typePkgMap[t]; pkg != nil {
+		return pkg.sources[t.Name.String()+"."+m.Name]
+	}
+	return nil
+}
+
+// loadSourceLinks reads where the types of r and their members are declared
+// and parses the sourceLinkTemplate of the config, if it has one. There are
+// no links to the source of a checkout with uncommitted changes, whose lines
+// may not match its commit.
+func (r *renderer) loadSourceLinks() error {
+	if r.config.SourceLinkTemplate == "" {
+		return nil
+	}
+	for _, pkg := range r.pkgs {
+		pkg.sources = make(map[string]*sourcePos)
+		for _, p := range pkg.GoPackages {
+			sources, err := readSourcePositions(p)
+			if err != nil {
+				return errors.Wrapf(err, "could not read the source of package %s", p.Path)
+			}
+			for k, pos := range sources {
+				pkg.sources[k] = pos
+			}
+		}
+	}
+
+	if r.provenance == nil || r.provenance.Commit == "" {
+		return nil
+	}
+	if r.provenance.Dirty {
+		klog.Warningf("not linking to the source: the API has uncommitted changes, its lines may not match commit %s", r.provenance.Commit)
+		return nil
+	}
+	tpl, err := template.New("sourceLinkTemplate").Parse(r.config.SourceLinkTemplate)
+	if err != nil {
+		return errors.Wrap(err, "sourceLinkTemplate failed to parse")
+	}
+	r.sourceLinkTemplate = tpl
+	return nil
+}
+
+// sourceLink expands the sourceLinkTemplate of the config for pos. It is
+// empty if there is no template, if the links are disabled by
+// loadSourceLinks, or if pos is not known.
+func (r *renderer) sourceLink(pos *sourcePos) (string, error) {
+	if r.sourceLinkTemplate == nil || pos == nil {
+		return "", nil
+	}
+	var b bytes.Buffer
+	if err := r.sourceLinkTemplate.Execute(&b, map[string]interface{}{
+		"Commit": r.provenance.Commit,
+		"Path":   pos.Path,
+		"Line":   pos.Line,
+	}); err != nil {
+		return "", errors.Wrap(err, "source link template execution error")
+	}
+	return b.String(), nil
+}
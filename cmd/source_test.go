@@ -0,0 +1,106 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/types"
+)
+
+const testSource = `package v1
+
+// Widget is a widget.
+type Widget struct {
+	metav1.TypeMeta ` + "`json:\",inline\"`" + `
+	*Base
+
+	Spec WidgetSpec ` + "`json:\"spec\"`" + `
+}
+
+type WidgetSpec struct {
+	Width, Height int32
+}
+
+type Mode string
+`
+
+func TestReadSourcePositions(t *testing.T) {
+	repo := testGitRepo(t)
+	dir := filepath.Join(repo, "api", "v1")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"types.go":      testSource,
+		"types_test.go": "package v1\n\ntype Fixture struct{}\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := readSourcePositions(&types.Package{Path: "example.com/api/v1", SourcePath: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos := func(line int) *sourcePos { return &sourcePos{Path: "api/v1/types.go", Line: line} }
+	want := map[string]*sourcePos{
+		"example.com/api/v1.Widget":            pos(4),
+		"example.com/api/v1.Widget.TypeMeta":   pos(5),
+		"example.com/api/v1.Widget.Base":       pos(6),
+		"example.com/api/v1.Widget.Spec":       pos(8),
+		"example.com/api/v1.WidgetSpec":        pos(11),
+		"example.com/api/v1.WidgetSpec.Width":  pos(12),
+		"example.com/api/v1.WidgetSpec.Height": pos(12),
+		"example.com/api/v1.Mode":              pos(15),
+	}
+	if !reflect.DeepEqual(got, want) {
+		for k, v := range got {
+			t.Errorf("got %s at %+v", k, *v)
+		}
+		t.Errorf("want %d positions", len(want))
+	}
+}
+
+func TestReadSourcePositionsOutsideGit(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "types.go"), []byte(testSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readSourcePositions(&types.Package{Path: "example.com/api/v1", SourcePath: dir})
+	if err != nil || got != nil {
+		t.Errorf("readSourcePositions() = %v, %v, want nothing", got, err)
+	}
+}
+
+func TestSourceLink(t *testing.T) {
+	pos := &sourcePos{Path: "api/v1/types.go", Line: 12}
+	tests := []struct {
+		name string
+		prov *provenance
+		want string
+	}{
+		{"clean checkout", &provenance{Commit: "abc1234"}, "https://example.com/blob/abc1234/api/v1/types.go#L12"},
+		{"dirty checkout", &provenance{Commit: "abc1234", Dirty: true}, ""},
+		{"no commit", &provenance{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRenderer(nil, GeneratorConfig{SourceLinkTemplate: "https://example.com/blob/{{ .Commit }}/{{ .Path }}#L{{ .Line }}"}, formatMarkdown)
+			r.provenance = tt.prov
+			if err := r.loadSourceLinks(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.sourceLink(pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("sourceLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
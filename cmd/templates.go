@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	refdocs "github.com/elastic/gen-crd-api-reference-docs"
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+
+	texttemplate "text/template"
+)
+
+// templateFile is a file of the template search path.
+type templateFile struct {
+	// Path is where the file comes from, for display. Built-in files are
+	// prefixed with "built-in:".
+	Path    string
+	Content string
+}
+
+// templateSearchPath returns the template files of format from the lowest to
+// the highest precedence: the built-in set first, then the -template-dir
+// directories from the last one to the first one. Parsing them in this order
+// lets every file override the named templates ("page", "packages", "type",
+// "members", ...) of the files before it, so a directory only needs to
+// contain the templates it customizes.
+func templateSearchPath(format string) ([]templateFile, error) {
+	files, err := readTemplateFiles(refdocs.Templates, path.Join("templates", format, "*.tpl"),
+		func(name string) string { return "built-in:" + name })
+	if err != nil {
+		return nil, err
+	}
+	for i := len(*flTemplateDirs) - 1; i >= 0; i-- {
+		dir := (*flTemplateDirs)[i]
+		fl, err := readTemplateFiles(os.DirFS(dir), "*.tpl",
+			func(name string) string { return filepath.Join(dir, name) })
+		if err != nil {
+			return nil, err
+		}
+		if len(fl) == 0 {
+			klog.Warningf("no .tpl files found in template dir %s", dir)
+		}
+		files = append(files, fl...)
+	}
+	return files, nil
+}
+
+// readTemplateFiles reads the files of fsys matching pattern, using
+// displayPath to name them.
+func readTemplateFiles(fsys fs.FS, pattern string, displayPath func(string) string) ([]templateFile, error) {
+	matches, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list templates matching %s", pattern)
+	}
+	var out []templateFile
+	for _, m := range matches {
+		b, err := fs.ReadFile(fsys, m)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read template %s", displayPath(m))
+		}
+		out = append(out, templateFile{Path: displayPath(m), Content: string(b)})
+	}
+	return out, nil
+}
+
+// parseTemplates parses the template search path of format.
+func parseTemplates(format string, funcs map[string]interface{}) (templateExecutor, error) {
+	files, err := templateSearchPath(format)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == formatHTML {
+		tpl := template.New("").Funcs(funcs)
+		for _, f := range files {
+			if _, err := tpl.New(f.Path).Parse(f.Content); err != nil {
+				return nil, errors.Wrapf(err, "parse error in %s", f.Path)
+			}
+		}
+		return tpl, nil
+	}
+
+	// text formats must not be HTML-escaped
+	tpl := texttemplate.New("").Funcs(funcs)
+	for _, f := range files {
+		if _, err := tpl.New(f.Path).Parse(f.Content); err != nil {
+			return nil, errors.Wrapf(err, "parse error in %s", f.Path)
+		}
+	}
+	return tpl, nil
+}
+
+// cloneTemplates returns a copy of the templates t parsed by parseTemplates,
+// with the functions funcs replaced.
+func cloneTemplates(t templateExecutor, funcs map[string]interface{}) (templateExecutor, error) {
+	switch t := t.(type) {
+	case *template.Template:
+		c, err := t.Clone()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to clone the templates")
+		}
+		return c.Funcs(funcs), nil
+	case *texttemplate.Template:
+		c, err := t.Clone()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to clone the templates")
+		}
+		return c.Funcs(funcs), nil
+	}
+	return nil, errors.Errorf("cannot clone templates of type %T", t)
+}
+
+// resolveTemplates returns the file each named template of format resolves
+// to, i.e. the last file of the search path that defines it.
+func resolveTemplates(format string, funcs map[string]interface{}) (map[string]string, error) {
+	files, err := templateSearchPath(format)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string)
+	for _, f := range files {
+		// html/template uses the text/template parser, so this lists the
+		// definitions of both kinds of files
+		tpl, err := texttemplate.New(f.Path).Funcs(funcs).Parse(f.Content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse error in %s", f.Path)
+		}
+		for _, t := range tpl.Templates() {
+			if t.Name() != f.Path {
+				out[t.Name()] = f.Path
+			}
+		}
+	}
+	return out, nil
+}
+
+// listTemplates implements the "templates" command, which prints which file
+// each named template resolves to with the given -format and -template-dir.
+func listTemplates() {
+	if err := validateTemplateFlags(); err != nil {
+		klog.Fatalf("%+v", err)
+	}
+	format := outputFormat()
+	if !containsString(templateFormats, format) {
+		klog.Fatalf("the %s format has no templates", format)
+	}
+	resolved, err := resolveTemplates(format, newRenderer(nil, GeneratorConfig{}, format).templateFuncs(nil))
+	if err != nil {
+		klog.Fatalf("%+v", err)
+	}
+
+	names := make([]string, 0, len(resolved))
+	for name := range resolved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "TEMPLATE\tSOURCE\n")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%s\n", name, resolved[name])
+	}
+	if err := w.Flush(); err != nil {
+		klog.Fatalf("failed to write: %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// setTemplateDirs sets -template-dir to dirs for the duration of the test.
+func setTemplateDirs(t *testing.T, dirs ...string) {
+	old := *flTemplateDirs
+	t.Cleanup(func() { *flTemplateDirs = old })
+	*flTemplateDirs = dirs
+}
+
+func TestResolveTemplates(t *testing.T) {
+	const typeTemplate = `{{ define "type" }}custom{{ end }}`
+	tests := []struct {
+		name string
+		// dirs are the files of each -template-dir, by file name
+		dirs []map[string]string
+		want map[string]string
+	}{
+		{
+			name: "built-in templates",
+			want: map[string]string{
+				"page": "built-in:templates/markdown/page.tpl",
+				"type": "built-in:templates/markdown/type.tpl",
+			},
+		},
+		{
+			name: "partial override",
+			dirs: []map[string]string{{"type.tpl": typeTemplate}},
+			want: map[string]string{
+				"page": "built-in:templates/markdown/page.tpl",
+				"type": "0/type.tpl",
+			},
+		},
+		{
+			name: "override in another file",
+			dirs: []map[string]string{{"custom.tpl": typeTemplate}},
+			want: map[string]string{
+				"page": "built-in:templates/markdown/page.tpl",
+				"type": "0/custom.tpl",
+			},
+		},
+		{
+			name: "first directory wins",
+			dirs: []map[string]string{{"type.tpl": typeTemplate}, {"type.tpl": typeTemplate}},
+			want: map[string]string{
+				"page": "built-in:templates/markdown/page.tpl",
+				"type": "0/type.tpl",
+			},
+		},
+		{
+			name: "later directory fills in",
+			dirs: []map[string]string{{"type.tpl": typeTemplate}, {"page.tpl": `{{ define "page" }}custom{{ end }}`}},
+			want: map[string]string{
+				"page": "1/page.tpl",
+				"type": "0/type.tpl",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			var dirs []string
+			for i, files := range tt.dirs {
+				dir := filepath.Join(root, string(rune('0'+i)))
+				for name, content := range files {
+					writeTestFile(t, filepath.Join(dir, name), content)
+				}
+				dirs = append(dirs, dir)
+			}
+			setTemplateDirs(t, dirs...)
+
+			resolved, err := resolveTemplates(formatMarkdown, newRenderer(nil, GeneratorConfig{}, formatMarkdown).templateFuncs(nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]string)
+			for name := range tt.want {
+				if rel, err := filepath.Rel(root, resolved[name]); err == nil {
+					got[name] = filepath.ToSlash(rel)
+				} else {
+					got[name] = resolved[name]
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveTemplates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		dirs   []string
+		want   string
+	}{
+		{"default", "", nil, formatHTML},
+		{"format flag", formatAsciiDoc, nil, formatAsciiDoc},
+		{"inferred from the directory", "", []string{"docs/markdown"}, formatMarkdown},
+		{"inferred from a later directory", "", []string{"docs/custom", "docs/asciidoc/"}, formatAsciiDoc},
+		{"format flag over the directory", formatHTML, []string{"docs/markdown"}, formatHTML},
+		{"unknown directory name", "", []string{"docs/custom"}, formatHTML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFlag(t, flFormat, tt.format)
+			setTemplateDirs(t, tt.dirs...)
+			if got := outputFormat(); got != tt.want {
+				t.Errorf("outputFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
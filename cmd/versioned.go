@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+)
+
+// taggedDocs is the documentation of a release, generated from a git tag of
+// the repository of -api-dir.
+type taggedDocs struct {
+	Tag    string `json:"tag"`
+	Commit string `json:"commit,omitempty"`
+	// Link is the link to the index page of the tag, or to its directory
+	// for the formats without an index page, relative to -out-dir.
+	Link string `json:"link"`
+}
+
+// versionedDocsCommand generates the documentation of every git tag of the
+// repository of -api-dir matching -tags, each into a subdirectory of
+// -out-dir named after the tag, and an index page linking to them.
+func versionedDocsCommand() {
+	config := readConfigFromFile()
+	if *flAPIDir == "" {
+		klog.Fatal("-api-dir not specified")
+	}
+	if *flOutDir == "" {
+		klog.Fatal("-out-dir not specified")
+	}
+	if err := validateTemplateFlags(); err != nil {
+		klog.Fatalf("%+v", err)
+	}
+
+	tags, err := listTags(*flAPIDir, *flTags)
+	if err != nil {
+		klog.Fatalf("%+v", err)
+	}
+	if len(tags) == 0 {
+		klog.Fatalf("no git tags match %q", *flTags)
+	}
+	var docs []*taggedDocs
+	for i, tag := range tags {
+		tagConfig := config
+		if config.SiteGenerator != nil {
+			tagConfig.SiteGenerator = config.SiteGenerator.forTag(tag, i)
+		}
+		d, err := generateTaggedDocs(tag, tagConfig)
+		if err != nil {
+			klog.Fatalf("failed to generate the documentation of %s: %+v", tag, err)
+		}
+		docs = append(docs, d)
+	}
+
+	index, err := renderTagIndex(docs, config)
+	if err != nil {
+		klog.Fatalf("%+v", err)
+	}
+	if err := outputToDir(*flOutDir, index); err != nil {
+		klog.Fatalf("%+v", err)
+	}
+}
+
+// listTags returns the tags of the git repository holding dir that match
+// pattern, the latest version first.
+func listTags(dir, pattern string) ([]string, error) {
+	out, err := git(dir, "tag", "--list", "--sort=-version:refname", pattern)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// generateTaggedDocs checks -api-dir out at tag and writes its documentation
+// to the subdirectory of -out-dir named after the tag.
+func generateTaggedDocs(tag string, config GeneratorConfig) (*taggedDocs, error) {
+	c, err := checkoutAPIDir(*flAPIDir, tag)
+	if err != nil {
+		return nil, err
+	}
+	defer c.remove()
+	pkgs, err := c.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &taggedDocs{Tag: tag, Link: tag + "/"}
+	if containsString(templateFormats, outputFormat()) {
+		d.Link = config.SiteGenerator.link(path.Join(tag, newSiteLayout(outputFormat(), false, config, nil).indexPage()), "")
+	}
+	prov, err := newProvenance(c.apiDir(), config)
+	if err != nil {
+		return nil, err
+	}
+	d.Commit = prov.Commit
+	// the examples must be of the Kinds of the tag
+	if config.ExamplesDir != "" {
+		dir, inRepo, err := c.worktreePath(config.ExamplesDir)
+		if err != nil {
+			return nil, err
+		}
+		if inRepo {
+			if _, err := os.Stat(dir); os.IsNotExist(err) {
+				klog.Infof("%s has no examplesDir %s", tag, config.ExamplesDir)
+				dir = ""
+			}
+			config.ExamplesDir = dir
+		}
+	}
+	pages, err := generateSite(pkgs, config, prov)
+	if err != nil {
+		return nil, err
+	}
+	if err := outputToDir(filepath.Join(*flOutDir, filepath.FromSlash(tag)), pages); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// renderTagIndex renders the index page linking to the documentation of
+// every tag with the "tagIndex" template, or as JSON for the formats
+// without templates.
+func renderTagIndex(docs []*taggedDocs, config GeneratorConfig) (map[string]string, error) {
+	var b bytes.Buffer
+	format := outputFormat()
+	if !containsString(templateFormats, format) {
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(docs); err != nil {
+			return nil, errors.Wrap(err, "failed to encode the index")
+		}
+		return map[string]string{"index.json": b.String()}, nil
+	}
+
+	tpl, err := parseTemplates(format, newRenderer(nil, config, format).templateFuncs(nil))
+	if err != nil {
+		return nil, err
+	}
+	var siteGenerator string
+	if sg := config.SiteGenerator; sg != nil {
+		siteGenerator = sg.Name
+	}
+	if err := tpl.ExecuteTemplate(&b, "tagIndex", map[string]interface{}{"tags": docs, "siteGenerator": siteGenerator}); err != nil {
+		return nil, errors.Wrap(err, "tagIndex template execution error")
+	}
+	index := newSiteLayout(format, false, config, nil).indexPage()
+	out := map[string]string{index: postProcess(b.String(), config)}
+	if config.SiteGenerator != nil {
+		if err := config.SiteGenerator.decorateTagIndex(out, index, docs); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"go/build"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testCommitDate = "2021-02-03T04:05:06Z"
+
+// testGit runs a git command in dir, committing at testCommitDate.
+func testGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+testCommitDate, "GIT_AUTHOR_DATE="+testCommitDate)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// setEnv sets the environment variable key for the duration of the test, or
+// unsets it if value is nil.
+func setEnv(t *testing.T, key string, value *string) {
+	old, ok := os.LookupEnv(key)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+	if value == nil {
+		os.Unsetenv(key)
+	} else {
+		os.Setenv(key, *value)
+	}
+}
+
+// testTaggedRepo returns the repository and the -api-dir of an API tagged
+// v1.0.0 with a Widget type, and v1.1.0 with a Gadget type too.
+func testTaggedRepo(t *testing.T) (repo, apiDir string) {
+	t.Helper()
+	// the repository must be in GOPATH to check its tags out, and gengo
+	// parses it in GOPATH mode
+	gopath := t.TempDir()
+	off := "off"
+	setEnv(t, "GO111MODULE", &off)
+	old := build.Default.GOPATH
+	t.Cleanup(func() { build.Default.GOPATH = old })
+	build.Default.GOPATH = gopath
+	repo = filepath.Join(gopath, "src", "example.com", "widgets")
+	apiDir = filepath.Join(repo, "api")
+	writeTestFile(t, filepath.Join(apiDir, "v1", "doc.go"), "// +groupName=widgets.example.com\npackage v1\n")
+	writeTestFile(t, filepath.Join(apiDir, "v1", "types.go"), "package v1\n\n// Widget is a widget.\ntype Widget struct{}\n")
+	testGit(t, repo, "init", "-q", "-b", "main")
+	testGit(t, repo, "add", ".")
+	testGit(t, repo, "commit", "-q", "-m", "widgets")
+	testGit(t, repo, "tag", "v1.0.0")
+	writeTestFile(t, filepath.Join(apiDir, "v1", "gadget.go"), "package v1\n\n// Gadget is a gadget.\ntype Gadget struct{}\n")
+	testGit(t, repo, "add", ".")
+	testGit(t, repo, "commit", "-q", "-m", "gadgets")
+	testGit(t, repo, "tag", "v1.1.0")
+	return repo, apiDir
+}
+
+func TestVersionedDocs(t *testing.T) {
+	repo, apiDir := testTaggedRepo(t)
+	outDir := t.TempDir()
+	setFlag(t, flAPIDir, apiDir)
+	setFlag(t, flOutDir, outDir)
+	setFlag(t, flFormat, formatMarkdown)
+
+	tags, err := listTags(apiDir, "v1.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"v1.1.0", "v1.0.0"}; strings.Join(tags, " ") != strings.Join(want, " ") {
+		t.Fatalf("listTags() = %v, want %v", tags, want)
+	}
+	var docs []*taggedDocs
+	for _, tag := range tags {
+		d, err := generateTaggedDocs(tag, GeneratorConfig{})
+		if err != nil {
+			t.Fatalf("generateTaggedDocs(%s) = %+v", tag, err)
+		}
+		docs = append(docs, d)
+	}
+
+	for _, tt := range []struct {
+		tag    string
+		gadget bool
+	}{
+		{"v1.1.0", true},
+		{"v1.0.0", false},
+	} {
+		readPage := func(path string) string {
+			b, err := ioutil.ReadFile(filepath.Join(outDir, tt.tag, filepath.FromSlash(path)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return string(b)
+		}
+		pkgPage := readPage("widgets.example.com/v1/index.md")
+		if !strings.Contains(pkgPage, "Widget is a widget.") || strings.Contains(pkgPage, "Gadget is a gadget.") != tt.gadget {
+			t.Errorf("%s documents Gadget: %v, want %v:\n%s", tt.tag, !tt.gadget, tt.gadget, pkgPage)
+		}
+		page := readPage("index.md")
+		commit, err := git(repo, "rev-parse", "--short", tt.tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(page, "`"+commit+"`") {
+			t.Errorf("%s page is not of commit %s:\n%s", tt.tag, commit, page)
+		}
+	}
+	if docs[0].Commit == docs[1].Commit {
+		t.Errorf("both tags are of commit %s", docs[0].Commit)
+	}
+
+	index, err := renderTagIndex(docs, GeneratorConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := index["index.md"]
+	i, j := strings.Index(page, "(v1.1.0/index.md)"), strings.Index(page, "(v1.0.0/index.md)")
+	if i < 0 || j < 0 || i > j {
+		t.Errorf("index does not link to v1.1.0 then v1.0.0:\n%s", page)
+	}
+}
+
+func TestGenerateTaggedDocsWriteError(t *testing.T) {
+	repo, apiDir := testTaggedRepo(t)
+	// the tag directory cannot be created in a file
+	outDir := filepath.Join(t.TempDir(), "docs")
+	writeTestFile(t, outDir, "")
+	setFlag(t, flAPIDir, apiDir)
+	setFlag(t, flOutDir, outDir)
+	setFlag(t, flFormat, formatMarkdown)
+
+	if _, err := generateTaggedDocs("v1.0.0", GeneratorConfig{}); err == nil {
+		t.Fatal("generateTaggedDocs() = nil, want an error")
+	}
+	worktrees, err := git(repo, "worktree", "list", "--porcelain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(worktrees, "worktree "); n != 1 {
+		t.Errorf("got %d worktrees, want the worktree of v1.0.0 removed:\n%s", n, worktrees)
+	}
+}
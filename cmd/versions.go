@@ -0,0 +1,176 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+
+	"k8s.io/gengo/types"
+	"k8s.io/klog"
+)
+
+var kubeVersionRegex = regexp.MustCompile(`^v(\d+)(?:(alpha|beta)(\d+))?$`)
+
+// groupVersions relates the Kinds an API group defines across its versions.
+type groupVersions struct {
+	Group string
+	// Versions of the group, oldest and least stable first.
+	Versions []string
+	Kinds    []*groupKind
+}
+
+// groupKind is a Kind of an API group.
+type groupKind struct {
+	Kind string
+	// Versions has one entry per version of the group, in the same order as
+	// groupVersions.Versions. Entries are nil for versions that do not
+	// define the Kind.
+	Versions []*kindVersion
+}
+
+// kindVersion is a Kind as defined in one version of its group.
+type kindVersion struct {
+	Version string
+	Type    *types.Type
+	// Storage is true for the version objects are persisted in. It is set
+	// by "+kubebuilder:storageversion", or implied if the Kind has a single
+	// version.
+	Storage bool
+	// Served is false if the version is marked
+	// "+kubebuilder:unservedversion".
+	Served bool
+	// Deprecated is true if the version is marked
+	// "+kubebuilder:deprecatedversion", with an optional warning.
+	Deprecated         bool
+	DeprecationWarning string
+}
+
+// buildGroupVersions computes the cross-version model of every API group in
+// pkgs, and the kindVersion of each visible Kind type.
+func buildGroupVersions(pkgs []*apiPackage, c GeneratorConfig) ([]*groupVersions, map[*types.Type]*kindVersion) {
+	groups := make(map[string]*groupVersions)
+	var groupNames []string
+	for _, pkg := range pkgs {
+		g, ok := groups[pkg.apiGroup]
+		if !ok {
+			g = &groupVersions{Group: pkg.apiGroup}
+			groups[pkg.apiGroup] = g
+			groupNames = append(groupNames, pkg.apiGroup)
+		}
+		g.Versions = append(g.Versions, pkg.apiVersion)
+	}
+	sort.Strings(groupNames)
+
+	kindVersions := make(map[*types.Type]*kindVersion)
+	out := make([]*groupVersions, 0, len(groups))
+	for _, name := range groupNames {
+		g := groups[name]
+		sort.Slice(g.Versions, func(i, j int) bool { return kubeVersionLess(g.Versions[i], g.Versions[j]) })
+
+		kinds := make(map[string]*groupKind)
+		var kindNames []string
+		for _, pkg := range pkgs {
+			if pkg.apiGroup != g.Group {
+				continue
+			}
+			col := indexOf(g.Versions, pkg.apiVersion)
+			for _, t := range pkg.Types {
+				if !isExportedType(t) || isListType(t) || hideType(t, c) {
+					continue
+				}
+				k, ok := kinds[t.Name.Name]
+				if !ok {
+					k = &groupKind{Kind: t.Name.Name, Versions: make([]*kindVersion, len(g.Versions))}
+					kinds[t.Name.Name] = k
+					kindNames = append(kindNames, t.Name.Name)
+				}
+				kv := newKindVersion(t, pkg.apiVersion)
+				k.Versions[col] = kv
+				kindVersions[t] = kv
+			}
+		}
+		sort.Strings(kindNames)
+		for _, name := range kindNames {
+			k := kinds[name]
+			checkStorageVersion(g.Group, k)
+			g.Kinds = append(g.Kinds, k)
+		}
+		out = append(out, g)
+	}
+	return out, kindVersions
+}
+
+func newKindVersion(t *types.Type, version string) *kindVersion {
+	lines := typeCommentLines(t)
+	kv := &kindVersion{
+		Version: version,
+		Type:    t,
+		Storage: hasMarker(lines, "kubebuilder:storageversion"),
+		Served:  !hasMarker(lines, "kubebuilder:unservedversion"),
+	}
+	if v, ok := lastMarkerValue(lines, "kubebuilder:deprecatedversion"); ok {
+		kv.Deprecated = true
+		kv.DeprecationWarning = markerArgs(v)["warning"]
+	}
+	return kv
+}
+
+// checkStorageVersion makes sure exactly one version of k is the storage
+// version, the way the API server requires it, and warns otherwise.
+func checkStorageVersion(group string, k *groupKind) {
+	var defined, storage []*kindVersion
+	for _, kv := range k.Versions {
+		if kv == nil {
+			continue
+		}
+		defined = append(defined, kv)
+		if kv.Storage {
+			storage = append(storage, kv)
+		}
+	}
+	switch {
+	case len(defined) == 1:
+		defined[0].Storage = true
+	case len(storage) == 0:
+		klog.Warningf("%s Kind %s is defined in %d versions but none is marked +kubebuilder:storageversion", group, k.Kind, len(defined))
+	case len(storage) > 1:
+		klog.Warningf("%s Kind %s has %d versions marked +kubebuilder:storageversion", group, k.Kind, len(storage))
+	}
+}
+
+// kubeVersionLess orders Kubernetes API versions from the oldest and least
+// stable to the newest: v1alpha1 < v1beta1 < v1 < v2alpha1 < v2. Versions
+// that do not follow the convention sort last, alphabetically.
+func kubeVersionLess(a, b string) bool {
+	ma, mb := kubeVersionRegex.FindStringSubmatch(a), kubeVersionRegex.FindStringSubmatch(b)
+	if ma == nil || mb == nil {
+		if ma != nil {
+			return true
+		} else if mb != nil {
+			return false
+		}
+		return a < b
+	}
+	if ma[1] != mb[1] {
+		return atoi(ma[1]) < atoi(mb[1])
+	}
+	stage := map[string]int{"alpha": 0, "beta": 1, "": 2}
+	if ma[2] != mb[2] {
+		return stage[ma[2]] < stage[mb[2]]
+	}
+	return atoi(ma[3]) < atoi(mb[3])
+}
+
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
+
+func indexOf(sl []string, str string) int {
+	for i, s := range sl {
+		if s == str {
+			return i
+		}
+	}
+	return -1
+}
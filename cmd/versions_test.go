@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/types"
+)
+
+func TestKubeVersionLess(t *testing.T) {
+	want := []string{"v1alpha1", "v1alpha2", "v1beta1", "v1", "v2alpha1", "v2beta10", "v2", "v10", "other", "v1.0"}
+	got := []string{"v2", "v1.0", "v1", "v10", "v2beta10", "other", "v1beta1", "v2alpha1", "v1alpha2", "v1alpha1"}
+	sort.Slice(got, func(i, j int) bool { return kubeVersionLess(got[i], got[j]) })
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sorted versions = %v, want %v", got, want)
+	}
+}
+
+func TestCheckStorageVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []*kindVersion
+		// want are the storage flags of the defined versions
+		want []bool
+	}{
+		{"single version", []*kindVersion{nil, {Version: "v1"}}, []bool{true}},
+		{"marked version", []*kindVersion{{Version: "v1beta1"}, {Version: "v1", Storage: true}}, []bool{false, true}},
+		{"no marked version", []*kindVersion{{Version: "v1beta1"}, {Version: "v1"}}, []bool{false, false}},
+		{"several marked versions", []*kindVersion{{Version: "v1beta1", Storage: true}, {Version: "v1", Storage: true}}, []bool{true, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkStorageVersion("widgets.example.com", &groupKind{Kind: "Widget", Versions: tt.versions})
+			var got []bool
+			for _, kv := range tt.versions {
+				if kv != nil {
+					got = append(got, kv.Storage)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("storage = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildGroupVersions(t *testing.T) {
+	v1Widget := testKind("Widget", "+kubebuilder:storageversion")
+	v1beta1Widget := testKind("Widget", "+kubebuilder:unservedversion", `+kubebuilder:deprecatedversion:warning="use v1"`)
+	v1beta1Gadget := testKind("Gadget")
+	list := testKind("WidgetList")
+	list.Members = []types.Member{testMember("Items", &types.Type{Kind: types.Slice, Elem: v1Widget}, `json:"items"`)}
+	pkgs := []*apiPackage{
+		{apiGroup: "widgets.example.com", apiVersion: "v1", Types: []*types.Type{v1Widget, list}},
+		{apiGroup: "widgets.example.com", apiVersion: "v1beta1", Types: []*types.Type{v1beta1Widget, v1beta1Gadget}},
+	}
+
+	groups, kindVersions := buildGroupVersions(pkgs, GeneratorConfig{})
+	if len(groups) != 1 {
+		t.Fatalf("got %d groups, want 1", len(groups))
+	}
+	g := groups[0]
+	if want := []string{"v1beta1", "v1"}; !reflect.DeepEqual(g.Versions, want) {
+		t.Errorf("versions = %v, want %v", g.Versions, want)
+	}
+	var kinds []string
+	for _, k := range g.Kinds {
+		kinds = append(kinds, k.Kind)
+	}
+	if want := []string{"Gadget", "Widget"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("kinds = %v, want %v", kinds, want)
+	}
+	if kv := g.Kinds[0].Versions; kv[0] == nil || kv[1] != nil || !kv[0].Storage {
+		t.Errorf("Gadget versions = %+v, want the v1beta1 storage version only", kv)
+	}
+	if _, ok := kindVersions[list]; ok {
+		t.Errorf("WidgetList has a kind version, want none")
+	}
+
+	want := map[*types.Type]kindVersion{
+		v1Widget:      {Version: "v1", Type: v1Widget, Storage: true, Served: true},
+		v1beta1Widget: {Version: "v1beta1", Type: v1beta1Widget, Deprecated: true, DeprecationWarning: "use v1"},
+	}
+	for typ, kv := range want {
+		if got := kindVersions[typ]; got == nil || *got != kv {
+			t.Errorf("kind version %s = %+v, want %+v", kv.Version, got, kv)
+		}
+	}
+}
+
+func TestVersionsWithoutKinds(t *testing.T) {
+	pkgs := []*apiPackage{
+		{apiGroup: "widgets.example.com", apiVersion: "v1", Types: []*types.Type{testStruct()}},
+		{apiGroup: "widgets.example.com", apiVersion: "v1beta1", Types: []*types.Type{testStruct()}},
+	}
+	for _, pkg := range pkgs {
+		pkg.GoPackages = []*types.Package{{Path: "example.com/api/" + pkg.apiVersion, Name: pkg.apiVersion}}
+	}
+	for _, format := range []string{formatHTML, formatMarkdown, formatAsciiDoc} {
+		t.Run(format, func(t *testing.T) {
+			r := newRenderer(pkgs, GeneratorConfig{}, format)
+			var b bytes.Buffer
+			if err := r.render(&b, &page{Kind: pageSingle, Packages: pkgs}, &provenance{}); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(b.String(), "widgets.example.com versions") {
+				t.Errorf("output has a versions table without Kinds:\n%s", b.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+// Package refdocs holds the templates and stylesheet that are built into the
+// crd-docs-generator binary.
+package refdocs
+
+import "embed"
+
+// Templates has a directory of built-in templates for each output format,
+// e.g. "templates/html".
+//
+//go:embed templates
+var Templates embed.FS
+
+// Stylesheet is the CSS the built-in HTML templates include in the page.
+//
+//go:embed css/k8s-api-ref-style.css
+var Stylesheet string
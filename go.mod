@@ -1,6 +1,6 @@
 module github.com/elastic/gen-crd-api-reference-docs
 
-go 1.15
+go 1.16
 
 require (
 	github.com/google/go-cmp v0.5.5 // indirect
@@ -10,4 +10,5 @@ require (
 	github.com/shurcooL/sanitized_anchor_name v1.0.0 // indirect
 	k8s.io/gengo v0.0.0-20201203183100-97869a43a9d9
 	k8s.io/klog v0.2.0
+	sigs.k8s.io/yaml v1.2.0
 )
@@ -0,0 +1,34 @@
+{{ define "members" -}}
+
+{{ range typeMembers . -}}
+{{ if not (hiddenMember .) -}}
+| {{ asciidocCell (asciidocCode (memberPath $ .)) }} _{{ asciidocLinkForType .Type }}_
+{{- with memberSourceLink $ . }} ({{ asciidocLink "source" . }}){{ end }}
+| {{ if fieldEmbedded . }}(Members of {{ asciidocCell (asciidocCode (fieldName .)) }} are embedded into this type.) {{ end }}
+{{- with memberOrigin $ . }}_(From {{ asciidocLinkForType . }}.)_ {{ end }}
+{{- if isOptionalMember . }}_(Optional)_ {{ end }}
+{{- asciidocCell (renderComments .CommentLines) }}
+{{ if eq .Type.Name.Name "ObjectMeta" }}
+Refer to the Kubernetes API documentation for the fields of the `metadata` field.
+{{ end }}
+{{- with memberDefault . }}
+Default: {{ asciidocCell (asciidocCode .String) }}
+{{ end }}
+{{- with memberEnum . }}
+Allowed values: {{ range $i, $v := .Allowed }}{{ if $i }}, {{ end }}{{ asciidocCell (asciidocCode $v.Display) }}{{ end }}
+{{ end }}
+{{- with memberValidation . }}
+Validation:
+
+{{ range .Rules -}}
+* {{ .Marker }}: {{ asciidocCell (asciidocCode .Value) }}
+{{ end }}
+{{- end }}
+{{- with expandMember $ . }}{{ with .Recursive }}
+_(Recursive, see {{ asciidocLinkForType . }}.)_
+{{ end }}{{ end }}
+{{- with expandMember $ . }}{{ with .Table }}{{ template "members" . }}{{ end }}{{ end -}}
+{{ end -}}
+{{ end -}}
+
+{{- end }}
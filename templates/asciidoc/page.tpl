@@ -0,0 +1,47 @@
+{{ define "page" -}}
+// Generated documentation. Please do not edit.
+
+{{ if eq .page.Kind "kind" -}}
+[id="api-reference-{{ anchorIDForType .page.Type }}"]
+{{ else if eq .page.Kind "package" -}}
+[id="api-reference-{{ safeIdentifier (packageAnchorID (index .page.Packages 0)) }}"]
+{{ else -}}
+[id="api-reference"]
+{{ end -}}
+== API Reference
+
+{{ if eq .page.Kind "single" "index" -}}
+.Packages
+{{ range .packages -}}
+* {{ asciidocLink (packageDisplayName .) (linkForPackage . (safeIdentifier (packageAnchorID .))) }}
+{{ range (visibleTypes (sortedTypes .Types)) -}}
+{{ if isExportedType . -}}
+** {{ asciidocLinkForType . }}
+{{ end -}}
+{{ end -}}
+{{ end }}
+
+{{ template "versions" . }}
+{{ else -}}
+xref:{{ linkForIndex }}[Back to the index]
+{{ end }}
+
+{{ with .page.Type }}
+// the Kind is directly under the page title, not under a package
+:leveloffset: -1
+
+{{ template "type" . }}
+:leveloffset: +1
+{{ end }}
+
+{{ template "packages" . }}
+
+Generated using link:https://github.com/company/project[`crd-docs-generator`]
+{{- with .provenance }}
+{{- with .GeneratorVersion }} {{ . }}{{ end }} on {{ .Timestamp }}
+{{- if .Commit }} from git commit `{{ .Commit }}`
+{{- if .Tag }} (tag `{{ .Tag }}`){{ else if .Branch }} (branch `{{ .Branch }}`){{ end }} of {{ .CommitDate }}
+{{- if .Dirty }}, with uncommitted changes{{ end }}
+{{- end }}, with config `{{ printf "%.12s" .ConfigHash }}`
+{{- end }}.
+{{ end }}
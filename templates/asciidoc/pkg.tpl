@@ -0,0 +1,19 @@
+{{ define "packages" -}}
+
+{{ range .page.Packages -}}
+[id="{{ safeIdentifier (packageAnchorID .) }}"]
+=== {{ packageDisplayName . }}
+
+{{ with (index .GoPackages 0) }}
+{{- with .DocComments }}
+{{ renderComments . }}
+{{ end }}
+{{- end }}
+
+{{ range (pageTypes .) -}}
+{{ template "type" . }}
+
+{{ end }}
+{{ end }}
+
+{{- end }}
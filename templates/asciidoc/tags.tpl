@@ -0,0 +1,11 @@
+{{ define "tagIndex" -}}
+// Generated documentation. Please do not edit.
+
+[id="api-reference"]
+== API Reference
+
+.Versions
+{{ range .tags -}}
+* link:{{ .Link }}[{{ .Tag }}]
+{{ end }}
+{{- end }}
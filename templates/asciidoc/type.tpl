@@ -0,0 +1,113 @@
+{{ define "type" -}}
+
+[id="{{ anchorIDForType . }}"]
+==== {{ .Name.Name }}
+{{- if eq .Kind "Alias" }} (`{{ .Underlying }}` alias){{ end }}
+{{- with kindVersion . }} `{{ .Version }}`{{ template "versionBadges" . }}{{ end }}
+
+{{ with typeSourceLink . -}}
+{{ asciidocLink "View source" . }}
+{{ end }}
+
+{{ with kindVersion . }}
+{{- if .Deprecated -}}
+WARNING: {{ with .DeprecationWarning }}{{ asciidocEscape . }}{{ else }}This version is deprecated.{{ end }}
+{{ end }}
+{{- end }}
+
+{{ with (typeReferences .) -}}
+.Appears In:
+****
+{{ range . -}}
+- {{ asciidocLinkForType . }}
+{{ end -}}
+****
+{{ end }}
+
+{{ renderComments .CommentLines }}
+
+{{ with resourceInfo . -}}
+{{ $resource := . -}}
+[cols="25,75"]
+|===
+| Scope | {{ if .Namespaced }}Namespaced{{ else }}Cluster-scoped{{ end }}
+| Plural | {{ asciidocCell (asciidocCode .Plural) }}
+| Singular | {{ asciidocCell (asciidocCode .Singular) }}
+{{ with .ShortNames -}}
+| Short names | {{ range $i, $v := . }}{{ if $i }}, {{ end }}{{ asciidocCell (asciidocCode $v) }}{{ end }}
+{{ end -}}
+{{ with .Categories -}}
+| Categories | {{ range $i, $v := . }}{{ if $i }}, {{ end }}{{ asciidocCell (asciidocCode $v) }}{{ end }}
+{{ end -}}
+{{ if or .StatusSubresource .Scale -}}
+| Subresources | {{ if .StatusSubresource }}`status`{{ end }}
+{{- with .Scale }}{{ if $resource.StatusSubresource }}, {{ end }}`scale` (replicas: {{ asciidocCell (asciidocCode .SpecPath) }}, status: {{ asciidocCell (asciidocCode .StatusPath) }}
+{{- with .SelectorPath }}, selector: {{ asciidocCell (asciidocCode .) }}{{ end }}){{ end }}
+{{ end -}}
+|===
+
+{{ with .PrinterColumns -}}
+.Columns shown by `kubectl get`
+[cols="20,15,30,35", options="header"]
+|===
+| Column | Type | JSONPath | Description
+{{ range . -}}
+| {{ asciidocCell (asciidocEscape .Name) }}{{ if .Priority }} _(wide)_{{ end }}
+| {{ .Type }}{{ with .Format }} ({{ . }}){{ end }}
+| {{ asciidocCell (asciidocCode .JSONPath) }}
+| {{ asciidocCell (asciidocEscape .Description) }}
+{{ end -}}
+|===
+{{ end }}
+{{ end }}
+
+{{ with (typeEnum .) -}}
+{{ if .Mismatch -}}
+NOTE: The values allowed by the `+kubebuilder:validation:Enum` marker do not match the constants declared for this type.
+
+{{ end -}}
+{{ $enum := . -}}
+[cols="25a,75a", options="header"]
+|===
+| Value | Description
+{{ range .Values -}}
+| {{ asciidocCell (asciidocCode .Display) }}
+| {{ with .Constant }}{{ asciidocCell (renderComments .CommentLines) }}{{ end }}
+{{- if $enum.Mismatch }}
+{{- if not .InMarker }} _(Not listed in the Enum marker.)_{{ end }}
+{{- if not .Constant }} _(No Go constant declared.)_{{ end }}
+{{- end }}
+{{ end -}}
+|===
+{{ end }}
+
+{{ if and (isExportedType .) (not (isListType .)) -}}
+.Example
+[source,yaml]
+----
+{{ kindExample . }}
+----
+{{ end }}
+
+{{ range typeExamples . -}}
+.{{ if .Field }}Example of {{ asciidocCode .Field }}{{ else }}Example{{ end }}{{ with .Title }}, {{ asciidocEscape . }}{{ end }}
+[source,yaml]
+----
+{{ .Content }}
+----
+
+{{ end }}
+
+{{ if .Members -}}
+[cols="25a,75a", options="header"]
+|===
+| Field | Description
+{{ if isExportedType . -}}
+| `apiVersion` _string_ | {{ asciidocCell (asciidocCode (apiGroup .)) }}
+| `kind` _string_ | {{ asciidocCell (asciidocCode .Name.Name) }}
+{{ end -}}
+{{ template "members" . -}}
+|===
+{{ end }}
+
+{{- end }}
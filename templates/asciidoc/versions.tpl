@@ -0,0 +1,46 @@
+{{ define "versions" -}}
+
+{{ range .groups -}}
+{{ if and (gt (len .Versions) 1) .Kinds -}}
+[id="{{ safeIdentifier .Group }}-versions"]
+=== {{ .Group }} versions
+
+[options="header"]
+|===
+| Kind{{ range .Versions }} | {{ . }}{{ end }}
+{{ range .Kinds -}}
+| {{ .Kind }}{{ range .Versions }} | {{ with . }}{{ asciidocLink .Version (linkForType .Type) }}{{ template "versionBadges" . }}{{ else }}—{{ end }}{{ end }}
+{{ end -}}
+|===
+
+{{ $group := .Group -}}
+{{ $versions := .Versions -}}
+{{ range .Kinds -}}
+{{ with compareKind . $versions -}}
+[id="{{ safeIdentifier $group }}-{{ safeIdentifier .Kind }}-fields"]
+==== {{ .Kind }} fields across versions
+
+[options="header"]
+|===
+| Field{{ range .Versions }} | {{ . }}{{ end }}
+{{ range .Rows -}}
+| {{ asciidocCode .Path }}{{ range .Cells }} | {{ with . }}{{ asciidocLink .Path (linkForType .Parent) }}{{ with .Member }} _{{ asciidocLinkForType .Type }}_{{ end }}{{ template "fieldChanges" . }}{{ else }}—{{ end }}{{ end }}
+{{ end -}}
+|===
+
+{{ end -}}
+{{ end -}}
+{{ end -}}
+{{ end -}}
+
+{{- end }}
+
+{{ define "versionBadges" -}}
+{{ if .Storage }} *storage*{{ end }}
+{{- if .Deprecated }} _deprecated_{{ end }}
+{{- if not .Served }} _not served_{{ end }}
+{{- end }}
+
+{{ define "fieldChanges" -}}
+{{ range .Changes }} *{{ . }}*{{ end }}
+{{- end }}
@@ -0,0 +1,65 @@
+{{ define "compat" }}
+<h1>API compatibility</h1>
+
+<p>Incompatible changes from <code>{{ .report.Base }}</code> to <code>{{ .report.Head }}</code>.</p>
+
+<h2>Violations</h2>
+
+{{ with .report.Violations }}
+<table>
+    <thead>
+        <tr>
+            <th>Rule</th>
+            <th>API version</th>
+            <th>Type</th>
+            <th>Field</th>
+            <th>Details</th>
+        </tr>
+    </thead>
+    <tbody>
+        {{- range . }}
+        <tr class="compat-violation">
+            <td>{{ .Rule }}</td>
+            <td><code>{{ .APIVersion }}</code></td>
+            <td>{{ with .Type }}<code>{{ . }}</code>{{ end }}</td>
+            <td>{{ with .Field }}<code>{{ . }}</code>{{ end }}</td>
+            <td>
+                {{- if .Aspect }}{{ .Aspect }}: <code>{{ .Old }}</code> → <code>{{ .New }}</code>
+                {{- else if .Old }}<code>{{ .Old }}</code>
+                {{- end -}}
+            </td>
+        </tr>
+        {{- end }}
+    </tbody>
+</table>
+{{ else }}
+<p>No violations.</p>
+{{ end }}
+
+{{ with .report.Exempted }}
+<h2>Exempted</h2>
+
+<table>
+    <thead>
+        <tr>
+            <th>Rule</th>
+            <th>API version</th>
+            <th>Type</th>
+            <th>Field</th>
+            <th>Exemption</th>
+        </tr>
+    </thead>
+    <tbody>
+        {{- range . }}
+        <tr class="compat-exempted">
+            <td>{{ .Rule }}</td>
+            <td><code>{{ .APIVersion }}</code></td>
+            <td>{{ with .Type }}<code>{{ . }}</code>{{ end }}</td>
+            <td>{{ with .Field }}<code>{{ . }}</code>{{ end }}</td>
+            <td>{{ .Exemption }}</td>
+        </tr>
+        {{- end }}
+    </tbody>
+</table>
+{{ end }}
+{{ end }}
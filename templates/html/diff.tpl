@@ -0,0 +1,37 @@
+{{ define "diff" }}
+<h1>API changes</h1>
+
+<p>Changes from <code>{{ .diff.Base }}</code> to <code>{{ .diff.Head }}</code>.</p>
+
+{{ with .diff.Changes }}
+<table>
+    <thead>
+        <tr>
+            <th>Change</th>
+            <th>API version</th>
+            <th>Type</th>
+            <th>Field</th>
+            <th>Details</th>
+        </tr>
+    </thead>
+    <tbody>
+        {{- range . }}
+        <tr class="change-{{ .Change }}">
+            <td>{{ .Change }} {{ .Subject }}</td>
+            <td><code>{{ .APIVersion }}</code></td>
+            <td>{{ with .Type }}<code>{{ . }}</code>{{ end }}</td>
+            <td>{{ with .Field }}<code>{{ . }}</code>{{ end }}</td>
+            <td>
+                {{- if .Aspect }}{{ .Aspect }}: <code>{{ .Old }}</code> → <code>{{ .New }}</code>
+                {{- else if .Old }}<code>{{ .Old }}</code>
+                {{- else if .New }}<code>{{ .New }}</code>
+                {{- end -}}
+            </td>
+        </tr>
+        {{- end }}
+    </tbody>
+</table>
+{{ else }}
+<p>No changes.</p>
+{{ end }}
+{{ end }}
@@ -1,6 +1,6 @@
 {{ define "members" }}
 
-{{ range .Members }}
+{{ range typeMembers . }}
 {{ if not (hiddenMember .)}}
 <tr>
     <td>
@@ -14,6 +14,9 @@
                 <span class="type">{{ typeDisplayName .Type }}<span>
             {{ end }}
         </em>
+        {{ with memberSourceLink $ . }}
+            <br/><a class="source-link" href="{{ . }}">View source</a>
+        {{ end }}
     </td>
     <td>
         {{ if fieldEmbedded . }}
@@ -22,18 +25,49 @@
             </p>
         {{ end}}
 
+        {{ with memberOrigin $ . }}
+            <p>
+                (From <a href="{{ linkForType . }}">{{ typeDisplayName . }}</a>.)
+            </p>
+        {{ end }}
+
         {{ if isOptionalMember .}}
             <em>(Optional)</em>
         {{ end }}
 
         {{ safe (renderComments .CommentLines) }}
 
+        {{ with memberDefault . }}
+            <p>Default: <code>{{ . }}</code></p>
+        {{ end }}
+
+        {{ with memberEnum . }}
+            <p>Allowed values:
+            {{- range $i, $v := .Allowed }}{{ if $i }},{{ end }} <code>{{ $v.Display }}</code>{{ end -}}
+            </p>
+        {{ end }}
+
+        {{ with memberValidation . }}
+            <p>Validation:</p>
+            <ul>
+            {{- range .Rules }}
+                <li>{{ .Marker }}: <code>{{ .Value }}</code></li>
+            {{- end }}
+            </ul>
+        {{ end }}
+
     {{ if and (eq (.Type.Name.Name) "ObjectMeta") }}
         Refer to the Kubernetes API documentation for the fields of the
         <code>metadata</code> field.
     {{ end }}
 
-    {{ if or (eq (fieldName .) "spec") }}
+    {{ with expandMember $ . }}
+        {{ with .Recursive }}
+            <p>
+                (Recursive, see <a href="{{ linkForType . }}">{{ typeDisplayName . }}</a>.)
+            </p>
+        {{ end }}
+        {{ with .Table }}
         <br/>
         <br/>
         <table>
@@ -44,9 +78,10 @@
                 </tr>
             </thead>
             <tbody>
-            {{ template "members" .Type }}
+            {{ template "members" . }}
             </tbody>
         </table>
+        {{ end }}
     {{ end }}
     </td>
 </tr>
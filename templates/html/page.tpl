@@ -4,9 +4,9 @@
 <html lang="en">
     <head>
         <meta charset="UTF-8">
-        <title>API Reference Docs</title>
+        <title>{{ if not (eq .page.Kind "single" "index") }}{{ .page.Title }} - {{ end }}API Reference Docs</title>
         <link rel="stylesheet" href="https://maxcdn.bootstrapcdn.com/bootstrap/4.3.1/css/bootstrap.min.css">
-        <link rel="stylesheet" href="css/k8s-api-ref-style.css" type="text/css">
+        <style>{{ stylesheet }}</style>
     </head>
     <body>
         <div id="sidebar-wrapper" class="side-nav side-bar-nav">
@@ -17,7 +17,7 @@
             <ul>
                 {{ range . }}
                 <li class="nav-level-1">
-                    <a class="nav-item" href="#{{- packageAnchorID . -}}">{{ packageDisplayName . }}</a>
+                    <a class="nav-item" href="{{- linkForPackage . (packageAnchorID .) -}}">{{ packageDisplayName . }}</a>
                     <ul>
                     {{- range (visibleTypes (sortedTypes .Types)) -}}
                         {{ if isExportedType . -}}
@@ -33,12 +33,27 @@
             {{ end}}
         </div>
         <div id="page-content-wrapper" class="body-content container">
+            {{ if eq .page.Kind "single" "index" }}
+                {{ template "versions" . }}
+            {{ end }}
+
+            {{ with .page.Type }}
+                {{ template "type" . }}
+            {{ end }}
+
             {{ template "packages" .  }}
 
             <div class="text-right">
                 <div>
                 Generated using <a href="https://github.com/company/project"><code>crd-docs-generator</code></a>
-                            {{ with .gitCommit }} on git commit <code>{{ . }}</code>{{end}}.
+                {{- with .provenance }}
+                    {{- with .GeneratorVersion }} {{ . }}{{ end }} on <time datetime="{{ .Timestamp }}">{{ .Timestamp }}</time>
+                    {{- if .Commit }} from git commit <code>{{ .Commit }}</code>
+                        {{- if .Tag }} (tag <code>{{ .Tag }}</code>){{ else if .Branch }} (branch <code>{{ .Branch }}</code>){{ end }}
+                        of <time datetime="{{ .CommitDate }}">{{ .CommitDate }}</time>
+                        {{- if .Dirty }}, with uncommitted changes{{ end }}
+                    {{- end }}, with config <code title="{{ .ConfigHash }}">{{ printf "%.12s" .ConfigHash }}</code>
+                {{- end }}.
                 </div>
             </div>
         </div>
@@ -1,7 +1,7 @@
 
 {{ define "packages" }}
 
-{{ range .packages }}
+{{ range .page.Packages }}
     <h1 id="{{- packageAnchorID . -}}">
         {{- packageDisplayName . -}}
     </h1>
@@ -14,7 +14,7 @@
         {{ end }}
     {{ end }}
 
-    {{ range (visibleTypes (sortedTypes .Types))}}
+    {{ range (pageTypes .)}}
         {{ template "type" .  }}
     {{ end }}
     <hr/>
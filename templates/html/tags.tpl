@@ -0,0 +1,26 @@
+{{ define "tagIndex" }}
+<!doctype html>
+
+<html lang="en">
+    <head>
+        <meta charset="UTF-8">
+        <title>API Reference Docs</title>
+        <link rel="stylesheet" href="https://maxcdn.bootstrapcdn.com/bootstrap/4.3.1/css/bootstrap.min.css">
+        <style>{{ stylesheet }}</style>
+    </head>
+    <body>
+        <div id="page-content-wrapper" class="body-content container">
+            <h1>API Reference</h1>
+            <p>Versions:</p>
+            <ul>
+                {{- range .tags }}
+                <li>
+                    <a href="{{ .Link }}">{{ .Tag }}</a>
+                    {{- with .Commit }} <code>{{ . }}</code>{{ end }}
+                </li>
+                {{- end }}
+            </ul>
+        </div>
+    </body>
+</html>
+{{ end }}
@@ -3,7 +3,19 @@
 <h3 id="{{ anchorIDForType . }}">
     {{- .Name.Name }}
     {{ if eq .Kind "Alias" }}(<code>{{.Underlying}}</code> alias){{ end -}}
+    {{ with kindVersion . }}<span class="badge badge-light">{{ .Version }}</span>{{ template "versionBadges" . }}{{ end -}}
 </h3>
+{{ with typeSourceLink . }}
+    <p class="source-link"><a href="{{ . }}">View source</a></p>
+{{ end }}
+{{ with kindVersion . }}
+{{ if .Deprecated }}
+    <div class="alert alert-warning col-md-8">
+        This version is deprecated.
+        {{ .DeprecationWarning }}
+    </div>
+{{ end }}
+{{ end }}
 {{ with (typeReferences .) }}
     <div class="alert alert-info col-md-8"><i class="fa fa-info-circle"></i> Appears In:
     <ul>
@@ -23,7 +35,79 @@
     {{ safe (renderComments .CommentLines) }}
 </div>
 
-{{ with (constantsOfType .) }}
+{{ with resourceInfo . }}
+{{ $resource := . }}
+<table>
+    <tbody>
+        <tr>
+            <td>Scope</td>
+            <td>{{ if .Namespaced }}Namespaced{{ else }}Cluster-scoped{{ end }}</td>
+        </tr>
+        <tr>
+            <td>Resource</td>
+            <td><code>{{ .Plural }}</code> (singular: <code>{{ .Singular }}</code>)</td>
+        </tr>
+        {{ with .ShortNames }}
+        <tr>
+            <td>Short names</td>
+            <td>{{ range $i, $v := . }}{{ if $i }}, {{ end }}<code>{{ $v }}</code>{{ end }}</td>
+        </tr>
+        {{ end }}
+        {{ with .Categories }}
+        <tr>
+            <td>Categories</td>
+            <td>{{ range $i, $v := . }}{{ if $i }}, {{ end }}<code>{{ $v }}</code>{{ end }}</td>
+        </tr>
+        {{ end }}
+        {{ if or .StatusSubresource .Scale }}
+        <tr>
+            <td>Subresources</td>
+            <td>
+                {{- if .StatusSubresource }}<code>status</code>{{ end -}}
+                {{- with .Scale -}}
+                    {{- if $resource.StatusSubresource }}, {{ end -}}
+                    <code>scale</code>
+                    (replicas: <code>{{ .SpecPath }}</code>, status: <code>{{ .StatusPath }}</code>
+                    {{- with .SelectorPath }}, selector: <code>{{ . }}</code>{{ end -}})
+                {{- end -}}
+            </td>
+        </tr>
+        {{ end }}
+    </tbody>
+</table>
+
+{{ with .PrinterColumns }}
+<p>Columns shown by <code>kubectl get</code>:</p>
+<table>
+    <thead>
+        <tr>
+            <th>Column</th>
+            <th>Type</th>
+            <th>JSONPath</th>
+            <th>Description</th>
+        </tr>
+    </thead>
+    <tbody>
+        {{- range . }}
+        <tr>
+            <td>{{ .Name }}{{ if .Priority }} <em>(wide)</em>{{ end }}</td>
+            <td>{{ .Type }}{{ with .Format }} ({{ . }}){{ end }}</td>
+            <td><code>{{ .JSONPath }}</code></td>
+            <td>{{ .Description }}</td>
+        </tr>
+        {{- end }}
+    </tbody>
+</table>
+{{ end }}
+{{ end }}
+
+{{ with (typeEnum .) }}
+{{ if .Mismatch }}
+<div class="alert alert-warning col-md-8">
+    The values allowed by the <code>+kubebuilder:validation:Enum</code> marker
+    do not match the constants declared for this type.
+</div>
+{{ end }}
 <table>
     <thead>
         <tr>
@@ -32,21 +116,38 @@
         </tr>
     </thead>
     <tbody>
-      {{- range . -}}
+      {{- $enum := . -}}
+      {{- range .Values -}}
       <tr>
         {{- /*
             renderComments implicitly creates a <p> element, so we
             add one to the display name as well to make the contents
             of the two cells align evenly.
         */ -}}
-        <td><p>{{ typeDisplayName . }}</p></td>
-        <td>{{ safe (renderComments .CommentLines) }}</td>
+        <td><p>{{ .Display }}</p></td>
+        <td>
+            {{- with .Constant }}{{ safe (renderComments .CommentLines) }}{{ end -}}
+            {{- if $enum.Mismatch -}}
+                {{- if not .InMarker }}<p><em>(Not listed in the Enum marker.)</em></p>{{ end -}}
+                {{- if not .Constant }}<p><em>(No Go constant declared.)</em></p>{{ end -}}
+            {{- end -}}
+        </td>
       </tr>
       {{- end -}}
     </tbody>
 </table>
 {{ end }}
 
+{{ if and (isExportedType .) (not (isListType .)) }}
+<p>Example:</p>
+<pre><code class="language-yaml">{{ kindExample . }}</code></pre>
+{{ end }}
+
+{{ range typeExamples . }}
+<p>{{ if .Field }}Example of <code>{{ .Field }}</code>{{ else }}Example{{ end }}{{ with .Title }}, {{ . }}{{ end }}:</p>
+<pre><code class="language-yaml">{{ .Content }}</code></pre>
+{{ end }}
+
 {{ if .Members }}
 <table>
     <thead>
@@ -0,0 +1,90 @@
+{{ define "versions" }}
+
+{{ range .groups }}
+{{ if and (gt (len .Versions) 1) .Kinds }}
+    <h2 id="{{ safeIdentifier .Group }}-versions">
+        {{- .Group }} versions
+    </h2>
+    <table>
+        <thead>
+            <tr>
+                <th>Kind</th>
+                {{- range .Versions }}
+                <th>{{ . }}</th>
+                {{- end }}
+            </tr>
+        </thead>
+        <tbody>
+            {{- range .Kinds }}
+            <tr>
+                <td>{{ .Kind }}</td>
+                {{- range .Versions }}
+                <td>
+                    {{- with . -}}
+                        <a href="{{ linkForType .Type }}">{{ .Version }}</a>
+                        {{- template "versionBadges" . -}}
+                    {{- else -}}
+                        &mdash;
+                    {{- end -}}
+                </td>
+                {{- end }}
+            </tr>
+            {{- end }}
+        </tbody>
+    </table>
+    {{ $group := .Group }}
+    {{ $versions := .Versions }}
+    {{ range .Kinds }}
+    {{ with compareKind . $versions }}
+    <h3 id="{{ safeIdentifier $group }}-{{ safeIdentifier .Kind }}-fields">
+        {{- .Kind }} fields across versions
+    </h3>
+    <table>
+        <thead>
+            <tr>
+                <th>Field</th>
+                {{- range .Versions }}
+                <th>{{ . }}</th>
+                {{- end }}
+            </tr>
+        </thead>
+        <tbody>
+            {{- range .Rows }}
+            <tr>
+                <td><code>{{ .Path }}</code></td>
+                {{- range .Cells }}
+                <td>
+                    {{- with . -}}
+                        <a href="{{ linkForType .Parent }}"><code>{{ .Path }}</code></a>
+                        {{- with .Member }}<br/><em>{{ typeDisplayName .Type }}</em>{{ end -}}
+                        {{- template "fieldChanges" . -}}
+                    {{- else -}}
+                        &mdash;
+                    {{- end -}}
+                </td>
+                {{- end }}
+            </tr>
+            {{- end }}
+        </tbody>
+    </table>
+    {{ end }}
+    {{ end }}
+{{ end }}
+{{ end }}
+
+{{ end }}
+
+{{ define "versionBadges" }}
+    {{- if .Storage }} <span class="badge badge-primary">storage</span>{{ end -}}
+    {{- if .Deprecated }} <span class="badge badge-warning" title="{{ .DeprecationWarning }}">deprecated</span>{{ end -}}
+    {{- if not .Served }} <span class="badge badge-secondary">not served</span>{{ end -}}
+{{ end }}
+
+{{ define "fieldChanges" }}
+    {{- range .Changes }}
+        {{- if eq . "added" }} <span class="badge badge-success">added</span>
+        {{- else if eq . "removed" }} <span class="badge badge-danger">removed</span>
+        {{- else }} <span class="badge badge-warning">{{ . }}</span>
+        {{- end }}
+    {{- end -}}
+{{ end }}
@@ -0,0 +1,33 @@
+{{ define "compat" -}}
+
+# API compatibility
+
+Incompatible changes from {{ markdownCode .report.Base }} to {{ markdownCode .report.Head }}.
+
+## Violations
+
+{{ with .report.Violations -}}
+| Rule | API version | Type | Field | Details |
+| --- | --- | --- | --- | --- |
+{{ range . -}}
+| {{ .Rule }} | {{ markdownCode .APIVersion }} | {{ with .Type }}{{ markdownCode . }}{{ end }} | {{ with .Field }}{{ markdownCode . }}{{ end }} |
+{{- if .Aspect }} {{ .Aspect }}: {{ markdownCode .Old }} → {{ markdownCode .New }}
+{{- else if .Old }} {{ markdownCode .Old }}
+{{- end }} |
+{{ end -}}
+{{ else -}}
+No violations.
+{{ end }}
+
+{{- with .report.Exempted }}
+
+## Exempted
+
+| Rule | API version | Type | Field | Exemption |
+| --- | --- | --- | --- | --- |
+{{ range . -}}
+| {{ .Rule }} | {{ markdownCode .APIVersion }} | {{ with .Type }}{{ markdownCode . }}{{ end }} | {{ with .Field }}{{ markdownCode . }}{{ end }} | {{ .Exemption }} |
+{{ end -}}
+{{ end }}
+
+{{- end }}
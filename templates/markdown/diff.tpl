@@ -0,0 +1,21 @@
+{{ define "diff" -}}
+
+# API changes
+
+Changes from {{ markdownCode .diff.Base }} to {{ markdownCode .diff.Head }}.
+
+{{ with .diff.Changes -}}
+| Change | API version | Type | Field | Details |
+| --- | --- | --- | --- | --- |
+{{ range . -}}
+| {{ .Change }} {{ .Subject }} | {{ markdownCode .APIVersion }} | {{ with .Type }}{{ markdownCode . }}{{ end }} | {{ with .Field }}{{ markdownCode . }}{{ end }} |
+{{- if .Aspect }} {{ .Aspect }}: {{ markdownCode .Old }} → {{ markdownCode .New }}
+{{- else if .Old }} {{ markdownCode .Old }}
+{{- else if .New }} {{ markdownCode .New }}
+{{- end }} |
+{{ end -}}
+{{ else -}}
+No changes.
+{{ end }}
+
+{{- end }}
@@ -1,56 +1,20 @@
-{{ define "members" }}
+{{ define "members" -}}
 
-{{ range .Members }}
-{{ if not (hiddenMember .)}}
-<tr>
-    <td>
-        <code>{{ fieldName . }}</code><br/>
-        <em>
-            {{ if linkForType .Type }}
-                <a href="{{ linkForType .Type}}">
-                    {{ typeDisplayName .Type }}
-                </a>
-            {{ else }}
-                <span class="type">{{ typeDisplayName .Type }}<span>
-            {{ end }}
-        </em>
-    </td>
-    <td>
-        {{ if fieldEmbedded . }}
-            <p>
-                (Members of <code>{{ fieldName . }}</code> are embedded into this type.)
-            </p>
-        {{ end}}
+{{ range typeMembers . -}}
+{{ if not (hiddenMember .) -}}
+| {{ markdownCode (memberPath $ .) }} _{{ markdownLink (typeDisplayName .Type) (linkForType .Type) }}_
+{{- with memberSourceLink $ . }} ([source]({{ . }})){{ end }} |
+{{- if fieldEmbedded . }} (Members of {{ markdownCode (fieldName .) }} are embedded into this type.){{ end }}
+{{- with memberOrigin $ . }} _(From {{ markdownLink (typeDisplayName .) (linkForType .) }}.)_{{ end }}
+{{- if isOptionalMember . }} _(Optional)_{{ end }}
+{{- with (renderComments .CommentLines) }} {{ markdownCell . }}{{ end }}
+{{- if eq .Type.Name.Name "ObjectMeta" }} Refer to the Kubernetes API documentation for the fields of the `metadata` field.{{ end }}
+{{- with memberDefault . }} Default: {{ markdownCode .String }}.{{ end }}
+{{- with memberEnum . }} Allowed values: {{ range $i, $v := .Allowed }}{{ if $i }}, {{ end }}{{ markdownCode $v.Display }}{{ end }}.{{ end }}
+{{- with memberValidation . }} Validation: {{ range $i, $r := .Rules }}{{ if $i }}, {{ end }}{{ $r.Marker }} {{ markdownCode $r.Value }}{{ end }}.{{ end }}
+{{- with expandMember $ . }}{{ with .Recursive }} _(Recursive, see {{ markdownLink (typeDisplayName .) (linkForType .) }}.)_{{ end }}{{ end }} |
+{{ with expandMember $ . }}{{ with .Table }}{{ template "members" . }}{{ end }}{{ end -}}
+{{ end -}}
+{{ end -}}
 
-        {{ if isOptionalMember .}}
-            <em>(Optional)</em>
-        {{ end }}
-
-        {{ safe (renderComments .CommentLines) }}
-
-    {{ if and (eq (.Type.Name.Name) "ObjectMeta") }}
-        Refer to the Kubernetes API documentation for the fields of the
-        <code>metadata</code> field.
-    {{ end }}
-
-    {{ if or (eq (fieldName .) "spec") }}
-        <br/>
-        <br/>
-        <table>
-            <thead>
-                <tr>
-                    <th>Field</th>
-                    <th>Description</th>
-                </tr>
-            </thead>
-            <tbody>
-            {{ template "members" .Type }}
-            </tbody>
-        </table>
-    {{ end }}
-    </td>
-</tr>
-{{ end }}
-{{ end }}
-
-{{ end }}
+{{- end }}
@@ -1,18 +1,35 @@
-{{ define "page" }}
-    Packages:
-    {{ with .packages}}
-        {{ range . }}
-        * {{ packageDisplayName . }}
-            {{- range (visibleTypes (sortedTypes .Types)) -}}
-                {{ if isExportedType . -}}
-                    * [{{ typeDisplayName . }}]({{ linkForType . }})
-                {{- end }}
-            {{- end -}}
-        {{ end }}
-    {{ end}}
-    {{ template "packages" .  }}
+{{ define "page" -}}
+{{ if not .siteGenerator -}}
+# API Reference
+{{ end -}}
+{{ if eq .page.Kind "single" "index" }}
+Packages:
+{{ range .packages }}
+- [{{ packageDisplayName . }}]({{ linkForPackage . (safeIdentifier (packageAnchorID .)) }})
+{{- range (visibleTypes (sortedTypes .Types)) }}
+{{- if isExportedType . }}
+  - {{ markdownLink (typeDisplayName .) (linkForType .) }}
+{{- end }}
+{{- end }}
+{{- end }}
 
+{{ template "versions" . }}
+{{ else if not .siteGenerator }}
+[Back to the index]({{ linkForIndex }})
+{{ end }}
+
+{{ with .page.Type }}
+{{ template "type" . }}
+{{ end }}
+
+{{ template "packages" . }}
 
-    Generated using <a href="https://github.com/company/project"><code>crd-docs-generator</code></a>
-                {{ with .gitCommit }} on git commit <code>{{ . }}</code>{{end}}.
+Generated using [`crd-docs-generator`](https://github.com/company/project)
+{{- with .provenance }}
+{{- with .GeneratorVersion }} {{ . }}{{ end }} on {{ .Timestamp }}
+{{- if .Commit }} from git commit `{{ .Commit }}`
+{{- if .Tag }} (tag `{{ .Tag }}`){{ else if .Branch }} (branch `{{ .Branch }}`){{ end }} of {{ .CommitDate }}
+{{- if .Dirty }}, with uncommitted changes{{ end }}
+{{- end }}, with config `{{ printf "%.12s" .ConfigHash }}`
+{{- end }}.
 {{ end }}
@@ -1,23 +1,18 @@
+{{ define "packages" -}}
 
-{{ define "packages" }}
+{{ range .page.Packages -}}
+## {{ packageDisplayName . }} {#{{ safeIdentifier (packageAnchorID .) }}}
 
-{{ range .packages }}
-    <h1 id="{{- packageAnchorID . -}}">
-        {{- packageDisplayName . -}}
-    </h1>
+{{ with (index .GoPackages 0) }}
+{{- with .DocComments }}
+{{ renderComments . }}
+{{ end }}
+{{- end }}
 
-    {{ with (index .GoPackages 0 )}}
-        {{ with .DocComments }}
-        <div>
-            {{ safe (renderComments .) }}
-        </div>
-        {{ end }}
-    {{ end }}
+{{ range (pageTypes .) -}}
+{{ template "type" . }}
 
-    {{ range (visibleTypes (sortedTypes .Types))}}
-        {{ template "type" .  }}
-    {{ end }}
-    <hr/>
+{{ end }}
 {{ end }}
 
-{{ end }}
\ No newline at end of file
+{{- end }}
@@ -0,0 +1,10 @@
+{{ define "tagIndex" -}}
+{{ if not .siteGenerator -}}
+# API Reference
+{{ end -}}
+
+Versions:
+{{ range .tags }}
+- [{{ .Tag }}]({{ .Link }})
+{{- end }}
+{{ end }}
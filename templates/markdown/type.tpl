@@ -1,84 +1,97 @@
-{{ define "type" }}
-
-<h3 id="{{ anchorIDForType . }}">
-    {{- .Name.Name }}
-    {{ if eq .Kind "Alias" }}(<code>{{.Underlying}}</code> alias){{ end -}}
-</h3>
-{{ with (typeReferences .) }}
-    <div class="alert alert-info col-md-8"><i class="fa fa-info-circle"></i> Appears In:
-    <ul>
-        <li>
-                {{- $prev := "" -}}
-                {{- range . -}}
-                    {{- if $prev -}}, {{ end -}}
-                    {{- $prev = . -}}
-                    <a href="{{ linkForType . }}">{{ typeDisplayName . }}</a>
-                {{- end -}}
-        </li>
-    </ul>
-    </div>
+{{ define "type" -}}
+
+### {{ .Name.Name }}
+{{- if eq .Kind "Alias" }} (`{{ .Underlying }}` alias){{ end }}
+{{- with kindVersion . }} `{{ .Version }}`{{ template "versionBadges" . }}{{ end }} {#{{ anchorIDForType . }}}
+
+{{ with typeSourceLink . -}}
+[View source]({{ . }})
+{{ end }}
+
+{{ with kindVersion . }}
+{{- if .Deprecated -}}
+> **Deprecated:** {{ with .DeprecationWarning }}{{ markdownEscape . }}{{ else }}this version is deprecated.{{ end }}
+{{ end }}
+{{- end }}
+
+{{ with (typeReferences .) -}}
+_Appears in:_ {{ range $i, $t := . }}{{ if $i }}, {{ end }}{{ markdownLink (typeDisplayName $t) (linkForType $t) }}{{ end }}
+{{ end }}
+
+{{ renderComments .CommentLines }}
+
+{{ with resourceInfo . -}}
+{{ $resource := . -}}
+| Resource | |
+| --- | --- |
+| Scope | {{ if .Namespaced }}Namespaced{{ else }}Cluster-scoped{{ end }} |
+| Plural | {{ markdownCode .Plural }} |
+| Singular | {{ markdownCode .Singular }} |
+{{ with .ShortNames -}}
+| Short names | {{ range $i, $v := . }}{{ if $i }}, {{ end }}{{ markdownCode $v }}{{ end }} |
+{{ end -}}
+{{ with .Categories -}}
+| Categories | {{ range $i, $v := . }}{{ if $i }}, {{ end }}{{ markdownCode $v }}{{ end }} |
+{{ end -}}
+{{ if or .StatusSubresource .Scale -}}
+| Subresources | {{ if .StatusSubresource }}`status`{{ end }}
+{{- with .Scale }}{{ if $resource.StatusSubresource }}, {{ end }}`scale` (replicas: {{ markdownCode .SpecPath }}, status: {{ markdownCode .StatusPath }}
+{{- with .SelectorPath }}, selector: {{ markdownCode . }}{{ end }}){{ end }} |
+{{ end }}
+
+{{ with .PrinterColumns -}}
+Columns shown by `kubectl get`:
+
+| Column | Type | JSONPath | Description |
+| --- | --- | --- | --- |
+{{ range . -}}
+| {{ markdownEscape .Name }}{{ if .Priority }} _(wide)_{{ end }} | {{ .Type }}{{ with .Format }} ({{ . }}){{ end }} | {{ markdownCode .JSONPath }} | {{ markdownCell (markdownEscape .Description) }} |
+{{ end }}
+{{ end }}
 {{ end }}
 
-<div>
-    {{ safe (renderComments .CommentLines) }}
-</div>
-
-{{ with (constantsOfType .) }}
-<table>
-    <thead>
-        <tr>
-            <th>Value</th>
-            <th>Description</th>
-        </tr>
-    </thead>
-    <tbody>
-      {{- range . -}}
-      <tr>
-        {{- /*
-            renderComments implicitly creates a <p> element, so we
-            add one to the display name as well to make the contents
-            of the two cells align evenly.
-        */ -}}
-        <td><p>{{ typeDisplayName . }}</p></td>
-        <td>{{ safe (renderComments .CommentLines) }}</td>
-      </tr>
-      {{- end -}}
-    </tbody>
-</table>
+{{ with (typeEnum .) -}}
+{{ if .Mismatch -}}
+> **Note:** the values allowed by the `+kubebuilder:validation:Enum` marker do not match the constants declared for this type.
+
+{{ end -}}
+{{ $enum := . -}}
+| Value | Description |
+| --- | --- |
+{{ range .Values -}}
+| {{ markdownCode .Display }} | {{ with .Constant }}{{ markdownCell (renderComments .CommentLines) }}{{ end }}
+{{- if $enum.Mismatch }}
+{{- if not .InMarker }} _(Not listed in the Enum marker.)_{{ end }}
+{{- if not .Constant }} _(No Go constant declared.)_{{ end }}
+{{- end }} |
+{{ end }}
 {{ end }}
 
-{{ if .Members }}
-<table>
-    <thead>
-        <tr>
-            <th>Field</th>
-            <th>Description</th>
-        </tr>
-    </thead>
-    <tbody>
-        {{ if isExportedType . }}
-        <tr>
-            <td>
-                <code>apiVersion</code></br>
-                <span class="type">string<span>
-            </td>
-            <td>
-                <code>
-                    {{apiGroup .}}
-                </code>
-            </td>
-        </tr>
-        <tr>
-            <td>
-                <code>kind</code></br>
-                <span class="type">string<span>
-            </td>
-            <td><code>{{.Name.Name}}</code></td>
-        </tr>
-        {{ end }}
-        {{ template "members" .}}
-    </tbody>
-</table>
+{{ if and (isExportedType .) (not (isListType .)) -}}
+Example:
+
+```yaml
+{{ kindExample . }}
+```
 {{ end }}
 
+{{ range typeExamples . -}}
+{{ if .Field }}Example of {{ markdownCode .Field }}{{ else }}Example{{ end }}{{ with .Title }}, {{ markdownEscape . }}{{ end }}:
+
+```yaml
+{{ .Content }}
+```
+
 {{ end }}
+
+{{ if .Members -}}
+| Field | Description |
+| --- | --- |
+{{ if isExportedType . -}}
+| `apiVersion` _string_ | {{ markdownCode (apiGroup .) }} |
+| `kind` _string_ | {{ markdownCode .Name.Name }} |
+{{ end -}}
+{{ template "members" . }}
+{{ end }}
+
+{{- end }}
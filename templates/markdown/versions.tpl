@@ -0,0 +1,38 @@
+{{ define "versions" -}}
+
+{{ range .groups -}}
+{{ if and (gt (len .Versions) 1) .Kinds -}}
+## {{ .Group }} versions {#{{ safeIdentifier .Group }}-versions}
+
+| Kind |{{ range .Versions }} {{ . }} |{{ end }}
+| --- |{{ range .Versions }} --- |{{ end }}
+{{ range .Kinds -}}
+| {{ .Kind }} |{{ range .Versions }} {{ with . }}[{{ .Version }}]({{ linkForType .Type }}){{ template "versionBadges" . }}{{ else }}—{{ end }} |{{ end }}
+{{ end }}
+{{ $group := .Group -}}
+{{ $versions := .Versions -}}
+{{ range .Kinds -}}
+{{ with compareKind . $versions -}}
+### {{ .Kind }} fields across versions {#{{ safeIdentifier $group }}-{{ safeIdentifier .Kind }}-fields}
+
+| Field |{{ range .Versions }} {{ . }} |{{ end }}
+| --- |{{ range .Versions }} --- |{{ end }}
+{{ range .Rows -}}
+| {{ markdownCode .Path }} |{{ range .Cells }} {{ with . }}{{ markdownLink .Path (linkForType .Parent) }}{{ with .Member }} _{{ markdownLink (typeDisplayName .Type) (linkForType .Type) }}_{{ end }}{{ template "fieldChanges" . }}{{ else }}—{{ end }} |{{ end }}
+{{ end }}
+{{ end -}}
+{{ end -}}
+{{ end -}}
+{{ end -}}
+
+{{- end }}
+
+{{ define "versionBadges" -}}
+{{ if .Storage }} **storage**{{ end }}
+{{- if .Deprecated }} _deprecated_{{ end }}
+{{- if not .Served }} _not served_{{ end }}
+{{- end }}
+
+{{ define "fieldChanges" -}}
+{{ range .Changes }} **{{ . }}**{{ end }}
+{{- end }}